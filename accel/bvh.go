@@ -0,0 +1,83 @@
+// Package accel builds acceleration structures for ray intersection.
+package accel
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/mhv2109/RayTracing/geometry"
+)
+
+// BVHNode is a node in a bounding volume hierarchy tree.
+type BVHNode struct {
+	Left, Right geometry.Hittable
+	Box         geometry.AABB
+}
+
+// NewBVH builds a BVH tree from a slice of hittable objects.
+func NewBVH(objects []geometry.Hittable) *BVHNode {
+	n := &BVHNode{}
+
+	axis := rand.Intn(3)
+	cmp := func(a, b geometry.Hittable) int {
+		ab := a.BoundingBox()
+		bb := b.BoundingBox()
+		var av, bv float64
+		switch axis {
+		case 0:
+			av, bv = ab.Min.X, bb.Min.X
+		case 1:
+			av, bv = ab.Min.Y, bb.Min.Y
+		default:
+			av, bv = ab.Min.Z, bb.Min.Z
+		}
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+		return 0
+	}
+
+	switch len(objects) {
+	case 1:
+		n.Left = objects[0]
+		n.Right = objects[0]
+	case 2:
+		if cmp(objects[0], objects[1]) <= 0 {
+			n.Left = objects[0]
+			n.Right = objects[1]
+		} else {
+			n.Left = objects[1]
+			n.Right = objects[0]
+		}
+	default:
+		slices.SortFunc(objects, cmp)
+		mid := len(objects) / 2
+		n.Left = NewBVH(objects[:mid])
+		n.Right = NewBVH(objects[mid:])
+	}
+
+	n.Box = geometry.SurroundingBox(n.Left.BoundingBox(), n.Right.BoundingBox())
+	return n
+}
+
+func (n *BVHNode) Hit(r geometry.Ray, tmin, tmax float64, hr *geometry.HitRecord) bool {
+	r.Stats.AddNodeVisit()
+	if !n.Box.Hit(r, tmin, tmax) {
+		return false
+	}
+
+	hitLeft := n.Left.Hit(r, tmin, tmax, hr)
+	if hitLeft {
+		tmax = hr.T
+	}
+	hitRight := n.Right.Hit(r, tmin, tmax, hr)
+
+	return hitLeft || hitRight
+}
+
+func (n *BVHNode) BoundingBox() geometry.AABB {
+	return n.Box
+}
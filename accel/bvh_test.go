@@ -0,0 +1,25 @@
+package accel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestIdentifyTagsHits(t *testing.T) {
+	objects := geometry.Identify([]geometry.Hittable{
+		geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5},
+		geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -3}, R: 0.5},
+	})
+	world := geometry.NewHittables(NewBVH(objects))
+
+	var hr geometry.HitRecord
+	if !world.Hit(geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}, 0.001, math.MaxFloat64, &hr) {
+		t.Fatalf("expected ray to hit world")
+	}
+	if hr.ID != 1 {
+		t.Fatalf("hit ID = %d, want 1", hr.ID)
+	}
+}
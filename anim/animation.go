@@ -0,0 +1,225 @@
+// Package anim keyframes camera parameters and object transforms.
+package anim
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Animatable is implemented by values that can be interpolated by a Track.
+type Animatable[T any] interface {
+	Add(T) T
+	Sub(T) T
+	MulS(float64) T
+}
+
+// Scalar is an animatable float64.
+type Scalar float64
+
+func (s Scalar) Add(o Scalar) Scalar {
+	return s + o
+}
+
+func (s Scalar) Sub(o Scalar) Scalar {
+	return s - o
+}
+
+func (s Scalar) MulS(t float64) Scalar {
+	return s * Scalar(t)
+}
+
+// Interpolation selects how a Track moves from one Key to the next.
+type Interpolation int
+
+const (
+	// Linear moves at constant speed between keys.
+	Linear Interpolation = iota
+
+	// Bezier follows a cubic Bézier curve whose handles are placed so that
+	// the curve passes smoothly through neighbouring keys (Catmull-Rom), and
+	// eases in and out of the first and last keys.
+	Bezier
+)
+
+var interpolationNames = map[Interpolation]string{
+	Linear: "linear",
+	Bezier: "bezier",
+}
+
+func (i Interpolation) String() string {
+	if name, ok := interpolationNames[i]; ok {
+		return name
+	}
+	return fmt.Sprintf("Interpolation(%d)", int(i))
+}
+
+// ParseInterpolation parses an interpolation name.
+func ParseInterpolation(s string) (Interpolation, error) {
+	for i, name := range interpolationNames {
+		if name == s {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown interpolation %q", s)
+}
+
+// Key is a keyframe: the value of a Track at a frame. Interp applies to the
+// segment starting at this key.
+type Key[T Animatable[T]] struct {
+	Frame  float64
+	Value  T
+	Interp Interpolation
+}
+
+// Track is a keyframed value. Before the first key and after the last, it
+// holds the first and last values.
+type Track[T Animatable[T]] struct {
+	keys []Key[T]
+}
+
+// NewTrack returns a Track through keys, which need not be sorted.
+func NewTrack[T Animatable[T]](keys ...Key[T]) *Track[T] {
+	keys = slices.Clone(keys)
+	slices.SortStableFunc(keys, func(a, b Key[T]) int {
+		switch {
+		case a.Frame < b.Frame:
+			return -1
+		case a.Frame > b.Frame:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return &Track[T]{keys}
+}
+
+// At returns the value of the track at frame, or def if t is nil or has no
+// keys.
+func (t *Track[T]) At(frame float64, def T) T {
+	if t == nil || len(t.keys) == 0 {
+		return def
+	}
+
+	var (
+		keys = t.keys
+		n    = len(keys)
+	)
+	if frame <= keys[0].Frame {
+		return keys[0].Value
+	}
+	if frame >= keys[n-1].Frame {
+		return keys[n-1].Value
+	}
+
+	// index of the first key after frame
+	i, _ := slices.BinarySearchFunc(keys, frame, func(k Key[T], f float64) int {
+		if k.Frame <= f {
+			return -1
+		}
+		return 1
+	})
+	var (
+		k0, k1 = keys[i-1], keys[i]
+		span   = k1.Frame - k0.Frame
+		u      = (frame - k0.Frame) / span
+	)
+
+	switch k0.Interp {
+	case Bezier:
+		var (
+			p1 = k0.Value.Add(t.slope(i - 1).MulS(span / 3))
+			p2 = k1.Value.Sub(t.slope(i).MulS(span / 3))
+		)
+		return bezier(k0.Value, p1, p2, k1.Value, u)
+	default:
+		return k0.Value.Add(k1.Value.Sub(k0.Value).MulS(u))
+	}
+}
+
+// slope returns the Catmull-Rom tangent, per frame, at key i. The first and
+// last keys have zero slope.
+func (t *Track[T]) slope(i int) T {
+	if i == 0 || i == len(t.keys)-1 {
+		var zero T
+		return zero
+	}
+	prev, next := t.keys[i-1], t.keys[i+1]
+	return next.Value.Sub(prev.Value).MulS(1 / (next.Frame - prev.Frame))
+}
+
+// bezier evaluates a cubic Bézier curve with de Casteljau's algorithm.
+func bezier[T Animatable[T]](p0, p1, p2, p3 T, u float64) T {
+	lerp := func(a, b T) T {
+		return a.Add(b.Sub(a).MulS(u))
+	}
+	var (
+		a, b, c = lerp(p0, p1), lerp(p1, p2), lerp(p2, p3)
+		d, e    = lerp(a, b), lerp(b, c)
+	)
+	return lerp(d, e)
+}
+
+// CameraParams are the parameters of NewCamera that can be animated.
+type CameraParams struct {
+	LookFrom, LookAt vecmath.Point3
+	VUp              vecmath.Vec3
+	VFov             float64
+	Aperture         float64
+	FocusDist        float64
+}
+
+// CameraAnimation keyframes CameraParams. Nil tracks leave the parameter
+// unchanged.
+type CameraAnimation struct {
+	LookFrom, LookAt          *Track[vecmath.Vec3]
+	VFov, Aperture, FocusDist *Track[Scalar]
+}
+
+// At returns base with the animated parameters set to their values at frame.
+func (a CameraAnimation) At(frame float64, base CameraParams) CameraParams {
+	base.LookFrom = a.LookFrom.At(frame, base.LookFrom)
+	base.LookAt = a.LookAt.At(frame, base.LookAt)
+	base.VFov = float64(a.VFov.At(frame, Scalar(base.VFov)))
+	base.Aperture = float64(a.Aperture.At(frame, Scalar(base.Aperture)))
+	base.FocusDist = float64(a.FocusDist.At(frame, Scalar(base.FocusDist)))
+	return base
+}
+
+// ParseFrames parses an inclusive frame range, "first-last", or a single
+// frame.
+func ParseFrames(s string) (first, last int, err error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	if first, err = strconv.Atoi(strings.TrimSpace(lo)); err != nil {
+		return 0, 0, fmt.Errorf("invalid frame range %q: %w", s, err)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	if last, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+		return 0, 0, fmt.Errorf("invalid frame range %q: %w", s, err)
+	}
+	if last < first {
+		return 0, 0, fmt.Errorf("invalid frame range %q: last frame before first", s)
+	}
+	return first, last, nil
+}
+
+// TransformAnimation keyframes a Transform. Nil tracks leave the
+// corresponding part of the Transform at its identity value.
+type TransformAnimation struct {
+	Translate, Rotate, Scale *Track[vecmath.Vec3]
+}
+
+// At returns the Transform at frame.
+func (a TransformAnimation) At(frame float64) geometry.Transform {
+	return geometry.Transform{
+		Translate: a.Translate.At(frame, geometry.Identity.Translate),
+		Rotate:    a.Rotate.At(frame, geometry.Identity.Rotate),
+		Scale:     a.Scale.At(frame, geometry.Identity.Scale),
+	}
+}
@@ -0,0 +1,139 @@
+package anim
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func vecAlmostEqual(a, b vecmath.Vec3) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
+
+func TestTrackLinear(t *testing.T) {
+	track := NewTrack(
+		Key[Scalar]{Frame: 10, Value: 2},
+		Key[Scalar]{Frame: 0, Value: 0},
+		Key[Scalar]{Frame: 20, Value: -2})
+
+	tests := []struct {
+		frame float64
+		want  Scalar
+	}{
+		{-5, 0},
+		{0, 0},
+		{5, 1},
+		{10, 2},
+		{15, 0},
+		{25, -2},
+	}
+	for _, tt := range tests {
+		if got := track.At(tt.frame, 99); !almostEqual(float64(got), float64(tt.want)) {
+			t.Errorf("At(%v) = %v, want %v", tt.frame, got, tt.want)
+		}
+	}
+}
+
+func TestTrackBezier(t *testing.T) {
+	track := NewTrack(
+		Key[vecmath.Vec3]{Frame: 0, Value: vecmath.Vec3{X: 0, Y: 0, Z: 0}, Interp: Bezier},
+		Key[vecmath.Vec3]{Frame: 10, Value: vecmath.Vec3{X: 10, Y: 0, Z: 0}, Interp: Bezier},
+		Key[vecmath.Vec3]{Frame: 20, Value: vecmath.Vec3{X: 20, Y: 0, Z: 0}})
+
+	// passes through keys
+	if got := track.At(10, vecmath.Vec3{}); !vecAlmostEqual(got, vecmath.Vec3{X: 10, Y: 0, Z: 0}) {
+		t.Errorf("At(10) = %v, want key value", got)
+	}
+	// symmetric keys give a symmetric curve
+	if got := track.At(10, vecmath.Vec3{}).X - track.At(5, vecmath.Vec3{}).X; !almostEqual(got, track.At(15, vecmath.Vec3{}).X-10) {
+		t.Errorf("curve is not symmetric about the middle key")
+	}
+	// eases out of the first key, so it starts slower than linear
+	if got := track.At(1, vecmath.Vec3{}).X; got >= 1 {
+		t.Errorf("At(1) = %v, want less than linear 1", got)
+	}
+	// and is smooth through the middle key
+	var (
+		before = track.At(10, vecmath.Vec3{}).X - track.At(9.999, vecmath.Vec3{}).X
+		after  = track.At(10.001, vecmath.Vec3{}).X - track.At(10, vecmath.Vec3{}).X
+	)
+	if math.Abs(before-after)/0.001 > 1e-2 {
+		t.Errorf("slope jumps at key: %v before, %v after", before/0.001, after/0.001)
+	}
+}
+
+func TestTrackNilDefault(t *testing.T) {
+	var track *Track[Scalar]
+	if got := track.At(3, 7); got != 7 {
+		t.Errorf("nil track At = %v, want default 7", got)
+	}
+}
+
+func TestCameraAnimation(t *testing.T) {
+	var (
+		base = CameraParams{LookFrom: vecmath.Point3{X: 0, Y: 0, Z: 1}, VUp: vecmath.Vec3{X: 0, Y: 1, Z: 0}, VFov: 40, FocusDist: 3}
+		anim = CameraAnimation{
+			VFov: NewTrack(Key[Scalar]{Frame: 0, Value: 20}, Key[Scalar]{Frame: 10, Value: 60}),
+		}
+		got = anim.At(5, base)
+	)
+	if got.VFov != 40 {
+		t.Errorf("VFov = %v, want 40", got.VFov)
+	}
+	if got.LookFrom != base.LookFrom || got.FocusDist != base.FocusDist {
+		t.Errorf("untracked parameters changed: %+v", got)
+	}
+}
+
+func TestParseFrames(t *testing.T) {
+	tests := []struct {
+		in          string
+		first, last int
+		ok          bool
+	}{
+		{"1-120", 1, 120, true},
+		{"7", 7, 7, true},
+		{" 3 - 4 ", 3, 4, true},
+		{"5-2", 0, 0, false},
+		{"a-b", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		first, last, err := ParseFrames(tt.in)
+		if (err == nil) != tt.ok || first != tt.first || last != tt.last {
+			t.Errorf("ParseFrames(%q) = %d, %d, %v", tt.in, first, last, err)
+		}
+	}
+}
+
+func TestParseInterpolation(t *testing.T) {
+	for _, i := range []Interpolation{Linear, Bezier} {
+		if got, err := ParseInterpolation(i.String()); err != nil || got != i {
+			t.Errorf("ParseInterpolation(%q) = %v, %v", i, got, err)
+		}
+	}
+	if _, err := ParseInterpolation("cubic"); err == nil {
+		t.Errorf("expected an error parsing an unknown interpolation")
+	}
+}
+
+func TestTransformAnimation(t *testing.T) {
+	anim := TransformAnimation{
+		Rotate: NewTrack(Key[vecmath.Vec3]{Frame: 0}, Key[vecmath.Vec3]{Frame: 120, Value: vecmath.Vec3{X: 0, Y: 360, Z: 0}}),
+	}
+	xf := anim.At(30)
+	if !vecAlmostEqual(xf.Rotate, vecmath.Vec3{X: 0, Y: 90, Z: 0}) {
+		t.Errorf("Rotate = %v, want (0,90,0)", xf.Rotate)
+	}
+	if xf.Scale != geometry.Identity.Scale || xf.Translate != geometry.Identity.Translate {
+		t.Errorf("untracked parts not identity: %+v", xf)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/render"
+	"github.com/mhv2109/RayTracing/scene"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func newBenchmarkCamera() render.Camera {
+	return render.NewCamera(
+		defaultWidth/10,
+		defaultHeight/10,
+		defaultSamples/10,
+		defaultDepth/10,
+		defaultJobs,
+		vecmath.Point3{X: 13, Y: 2, Z: 3},
+		vecmath.Point3{X: 0, Y: 0, Z: 0},
+		vecmath.Vec3{X: 0, Y: 1, Z: 0},
+		20.0,
+		0.1,
+		10.0)
+}
+
+func BenchmarkRender(b *testing.B) {
+	var (
+		world = scene.Accelerate(scene.Random(1, material.Lambertian))
+		cam   = newBenchmarkCamera()
+	)
+
+	b.Run("render", func(b *testing.B) {
+		for _ = range cam.Render(world) {
+		}
+	})
+}
@@ -0,0 +1,926 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/mhv2109/RayTracing/anim"
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/render"
+	"github.com/mhv2109/RayTracing/scene"
+)
+
+var (
+	// cmdline args
+	imgWidth   int
+	imgHeight  int
+	samples    int
+	depth      int
+	jobs       int
+	simpleDiff bool
+	rrDepth    int
+	noRR       bool
+	spectral   bool
+	cpuprofile string
+	outputFile string
+	aovList    string
+	denoise    bool
+	addr       string
+	passSize   int
+	sceneSeed  int64
+	checkpoint string
+	cpEvery    time.Duration
+	resume     string
+	sceneFile  string
+	workers    string
+	tileSize   int
+	frames     string
+	turntable  int
+	fps        float64
+	statsFmt   string
+	statsFile  string
+	timeLimit  time.Duration
+	cropWindow string
+	composite  string
+	projection string
+	blades     int
+	bladeRot   float64
+	bokehMask  string
+	fstop      float64
+	shutter    string
+	iso        float64
+	whiteBal   float64
+	focus      string
+	stereo     string
+	interoc    float64
+	converge   float64
+	vignetting float64
+	chromatic  float64
+
+	// parsed -crop, -projection, -stereo, -shutter and loaded -composite, if
+	// set
+	cropOpt       render.CameraOpt
+	projType      render.ProjectionType
+	stereoLayout  render.StereoLayout
+	shutterSpeed  float64
+	compositeBase *image.RGBA
+
+	// defaults
+	defaultWidth   = 2560
+	defaultHeight  = 1440
+	defaultSamples = 500
+	defaultDepth   = 50
+	defaultJobs    = 4 * runtime.NumCPU() // determined by benchmarking
+)
+
+func init() {
+	flag.IntVar(&imgWidth, "width", 2560, "output image width")
+	flag.IntVar(&imgHeight, "height", 1440, "output image height")
+	flag.IntVar(&samples, "samples", 500, "number of samples per pixel")
+	flag.IntVar(&depth, "depth", 50, "number of ray bounces to calculate")
+	flag.IntVar(&jobs, "jobs", defaultJobs, "number of jobs for rendering")
+	flag.BoolVar(&simpleDiff, "simple", false, "use simple diffusion calculation")
+	flag.IntVar(&rrDepth, "rrdepth", render.DefaultRussianRouletteDepth, "number of ray bounces before Russian roulette may terminate a path")
+	flag.BoolVar(&noRR, "norr", false, "disable Russian roulette path termination")
+	flag.BoolVar(&spectral, "spectral", false, "trace sampled wavelengths instead of RGB, for dispersion")
+	flag.StringVar(&cpuprofile, "cpuprofile", "", "create a CPU profile and save to file")
+	flag.StringVar(&outputFile, "output", "", "output file, defaults to stdout")
+	flag.StringVar(&aovList, "aov", "", "comma-separated AOVs to write as PFM files (depth, normal, position, albedo, id)")
+	flag.BoolVar(&denoise, "denoise", false, "denoise the image after rendering, guided by the albedo and normal AOVs")
+	flag.StringVar(&addr, "addr", "localhost:8080", "serve/worker mode: address to listen on")
+	flag.IntVar(&passSize, "pass", 1, "serve/checkpoint mode: samples per pixel rendered per pass")
+	flag.Int64Var(&sceneSeed, "seed", 0, "seed for the random scene and the samples, 0 picks one at random")
+	flag.StringVar(&checkpoint, "checkpoint", "", "periodically save render progress to this file")
+	flag.DurationVar(&cpEvery, "checkpointevery", time.Minute, "interval between checkpoints")
+	flag.StringVar(&resume, "resume", "", "continue a render from this checkpoint file, with the same flags but for -samples, which may be raised to add samples")
+	flag.StringVar(&sceneFile, "scene", "", "JSON scene file to render instead of the random scene")
+	flag.StringVar(&workers, "workers", "", "comma-separated worker addresses to distribute the render across")
+	flag.IntVar(&tileSize, "tile", 32, "distributed mode: tile size in pixels")
+	flag.StringVar(&frames, "frames", "", "render an animation frame range, e.g. 1-120, to numbered files named after -output, e.g. out.%04d.ppm")
+	flag.IntVar(&turntable, "turntable", 120, "frames mode: frames per revolution of the scene, 0 for none; -scene files, which can keyframe the camera, only spin if set")
+	flag.Float64Var(&fps, "fps", 24, "frames per second of the animation, which with -shutter sets how much moving objects and cameras blur; 0 for none")
+	flag.StringVar(&statsFmt, "stats", "", "print render statistics when done, as a \"summary\" table or \"json\"")
+	flag.StringVar(&statsFile, "statsfile", "", "write -stats output to this file instead of stderr")
+	flag.StringVar(&cropWindow, "crop", "", "render only the window x0,y0,x1,y1 from the top left, in pixels or, with decimal points, fractions of the image")
+	flag.StringVar(&composite, "composite", "", "paste the -crop window over this full size PPM image instead of writing the window alone")
+	flag.StringVar(&projection, "projection", "perspective", "camera projection: perspective, orthographic, fisheye or equirectangular; overrides the -scene file's")
+	flag.IntVar(&blades, "blades", 0, "number of aperture blades, for polygonal bokeh; 0 is round")
+	flag.Float64Var(&bladeRot, "bladerotation", 0, "rotation of the aperture blades in degrees")
+	flag.StringVar(&bokehMask, "bokehmask", "", "PNG, JPEG or PPM image of the aperture shape, overriding -blades")
+	flag.Float64Var(&fstop, "fstop", 0, "lens f-number, setting the aperture and exposure; 0 keeps the default aperture")
+	flag.StringVar(&shutter, "shutter", "", "shutter speed in seconds, e.g. 1/250, setting exposure and, with -fps, motion blur (default 1/100)")
+	flag.Float64Var(&iso, "iso", render.ReferenceISO, "sensor sensitivity")
+	flag.Float64Var(&whiteBal, "whitebalance", 0, "color temperature in kelvin that renders as white, 0 for none")
+	flag.StringVar(&focus, "focus", "", "focus on what is seen at the image center (\"auto\"), at a pixel \"x,y\", or on a named object, e.g. ground, glass, matte or metal in the random scene")
+	flag.StringVar(&stereo, "stereo", "none", "render a stereo pair, side by side (\"sbs\") or left eye on top (\"tb\"); an omni-directional stereo panorama with -projection equirectangular")
+	flag.Float64Var(&interoc, "interocular", 0.064, "stereo mode: distance between the eyes, in scene units")
+	flag.Float64Var(&converge, "convergence", 0, "stereo mode: distance at which the eyes' images line up, 0 for the focus distance")
+	flag.Float64Var(&vignetting, "vignetting", 0, "strength of the lens's natural vignetting, 0 for none to 1 for physical")
+	flag.Float64Var(&chromatic, "chromatic", 0, "lateral chromatic aberration: red is magnified by 1+amount and blue by 1-amount, e.g. 0.005")
+	flag.DurationVar(&timeLimit, "time-limit", 0, "stop each image after this long, with as many samples per pixel as fit, up to -samples")
+}
+
+// settings collects the flags that define the image, so that worker processes
+// can reproduce it and a resumed render can be checked against the checkpoint
+// it continues.
+func settings() render.RenderSettings {
+	s := render.RenderSettings{
+		Width:           imgWidth,
+		Height:          imgHeight,
+		Samples:         samples,
+		Depth:           depth,
+		RRDepth:         rrDepth,
+		Spectral:        spectral,
+		SimpleDiffusion: simpleDiff,
+		Seed:            sceneSeed,
+		Scene:           sceneFile,
+		Stats:           statsFmt != "",
+		Projection:      projType,
+
+		Blades:        blades,
+		BladeRotation: bladeRot,
+		BokehMask:     bokehMask,
+
+		Photo: render.Photographic{FStop: fstop, ShutterSpeed: shutterSpeed, ISO: iso, WhiteBalance: whiteBal},
+		Focus: focus,
+
+		Stereo:      stereoLayout,
+		Interocular: interoc,
+		Convergence: converge,
+
+		Vignetting:          vignetting,
+		ChromaticAberration: chromatic,
+
+		FPS: fps,
+	}
+	if frames != "" && (sceneFile == "" || isFlagSet("turntable")) {
+		s.Turntable = turntable
+	}
+	if noRR {
+		s.RRDepth = -1
+	}
+	return s
+}
+
+// isFlagSet reports whether the named flag was given on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// newCamera returns the camera s describes, looking at the scene from view,
+// which move moves while the shutter is open if non-nil.
+func newCamera(s render.RenderSettings, view anim.CameraParams, move func(float64, render.View) render.View) (render.Camera, error) {
+	opts := []render.CameraOpt{
+		render.RussianRoulette(s.RRDepth),
+		render.Spectral(s.Spectral),
+		render.CollectStats(s.Stats),
+		render.Project(s.Projection),
+		render.Photo(s.Photo),
+		render.Stereo(s.Stereo, s.Interocular, s.Convergence),
+		render.Vignetting(s.Vignetting),
+		render.ChromaticAberration(s.ChromaticAberration),
+
+		// every frame of an animation draws fresh samples
+		render.Seed(uint64(s.Seed) + uint64(s.Frame)),
+		render.Motion(float64(s.Frame), s.FPS, move),
+	}
+	if cropOpt != nil {
+		opts = append(opts, cropOpt)
+	}
+	if s.BokehMask != "" {
+		mask, err := loadApertureMask(s.BokehMask)
+		if err != nil {
+			return render.Camera{}, fmt.Errorf("could not load -bokehmask: %w", err)
+		}
+		opts = append(opts, render.Bokeh(mask))
+	} else if s.Blades > 0 {
+		opts = append(opts, render.Bokeh(render.AperturePolygon{Blades: s.Blades, Rotation: s.BladeRotation}))
+	}
+
+	cam := render.NewCamera(
+		s.Width,
+		s.Height,
+		s.Samples,
+		s.Depth,
+		jobs,
+		view.LookFrom,
+		view.LookAt,
+		view.VUp,
+		view.VFov,
+		view.Aperture,
+		view.FocusDist,
+		opts...)
+	if cam.Crop().Size() == 0 {
+		return render.Camera{}, errors.New("-crop window is outside the image")
+	}
+	return cam, nil
+}
+
+// sceneCache keeps the last scene file loaded, so that main, every frame and
+// every build on a worker share one load of it.
+type sceneCache struct {
+	mu   sync.Mutex
+	path string
+	file *scene.File
+}
+
+// scenes caches the -scene file, or a worker's.
+var scenes sceneCache
+
+// load returns the scene file at path, loading it unless it was the last one.
+// The file is shared, and must not be changed.
+func (c *sceneCache) load(path string) (*scene.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil || c.path != path {
+		f, err := scene.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		c.path, c.file = path, f
+	}
+	return c.file, nil
+}
+
+// sceneBuilder returns the SceneBuilder shared by the coordinator and
+// workers. Build times are added to st, if non-nil.
+func sceneBuilder(st *render.Stats) render.SceneBuilder {
+	return func(s render.RenderSettings) (render.Camera, *geometry.Hittables, error) {
+		dt := material.Lambertian
+		if s.SimpleDiffusion {
+			dt = material.SimpleDiffusion
+		}
+
+		var (
+			start = time.Now()
+			file  *scene.File
+			world *geometry.Hittables
+			view  = scene.DefaultCamera
+			move  func(float64, render.View) render.View
+		)
+		if s.Scene != "" {
+			var err error
+			if file, err = scenes.load(s.Scene); err != nil {
+				return render.Camera{}, nil, fmt.Errorf("could not load scene: %w", err)
+			}
+			view, move = file.CameraAnimation.At(float64(s.Frame), file.Camera), cameraMotion(file)
+		}
+
+		// objects move for as long as the shutter is open
+		cam, err := newCamera(s, view, move)
+		if err != nil {
+			return render.Camera{}, nil, err
+		}
+		open, shut := cam.Shutter()
+
+		if file != nil {
+			world = file.WorldDuring(open, shut)
+		} else {
+			world = scene.Random(s.Seed, dt)
+		}
+		objects := world.Objects
+		st.Since("scene", start)
+
+		start = time.Now()
+		world = scene.Accelerate(world)
+		st.Since("bvh", start)
+
+		if s.Turntable > 0 {
+			world = scene.Turntable(world, s.Turntable, open, shut)
+		}
+
+		if s.Focus != "" {
+			cam, err = focusCamera(cam, s, world, objects)
+		}
+		return cam, world, err
+	}
+}
+
+// cameraMotion moves the view along f's camera keys while the shutter is
+// open, or is nil if the camera is not keyed. The aperture and focus are kept
+// as they are at the frame, where -focus may have set them.
+func cameraMotion(f *scene.File) func(float64, render.View) render.View {
+	if f.CameraAnimation == (anim.CameraAnimation{}) {
+		return nil
+	}
+	return func(t float64, v render.View) render.View {
+		p := f.CameraAnimation.At(t, f.Camera)
+		v.LookFrom, v.LookAt, v.VFov = p.LookFrom, p.LookAt, p.VFov
+		return v
+	}
+}
+
+// focusCamera focuses cam as -focus asks: on what world shows at the image
+// center or a pixel, or on one of the scene's named objects.
+func focusCamera(cam render.Camera, s render.RenderSettings, world *geometry.Hittables, objects []geometry.Hittable) (render.Camera, error) {
+	if s.Focus == "auto" {
+		focused, ok := cam.AutoFocus(world)
+		if !ok {
+			log.Printf("warning: nothing to focus on at the image center; keeping the default focus")
+		}
+		return focused, nil
+	}
+	if xs, ys, isPixel := strings.Cut(s.Focus, ","); isPixel {
+		x, errX := strconv.Atoi(strings.TrimSpace(xs))
+		y, errY := strconv.Atoi(strings.TrimSpace(ys))
+		if err := errors.Join(errX, errY); err != nil {
+			return cam, fmt.Errorf("invalid -focus pixel: %w", err)
+		}
+		focused, ok := cam.AutoFocusPixel(world, x, y)
+		if !ok {
+			log.Printf("warning: nothing to focus on at pixel %d,%d; keeping the default focus", x, y)
+		}
+		return focused, nil
+	}
+
+	obj, ok := geometry.FindNamed(objects, s.Focus)
+	if !ok {
+		return cam, fmt.Errorf("invalid -focus: no object named %q", s.Focus)
+	}
+	if s.Turntable > 0 {
+		// spin the object alone, to find where it is in this frame
+		alone := geometry.NewHittables(obj)
+		open, shut := cam.Shutter()
+		return cam.FocusOnObject(scene.Turntable(&alone, s.Turntable, open, shut)), nil
+	}
+	return cam.FocusOnObject(obj), nil
+}
+
+// withTimeLimit returns a context for rendering one image, cancelled after
+// -time-limit if set.
+func withTimeLimit(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeLimit > 0 {
+		return context.WithTimeout(ctx, timeLimit)
+	}
+	return context.WithCancel(ctx)
+}
+
+// stopped logs why a render stopped early, if it did, before what was
+// rendered is written out.
+func stopped(err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Printf("time limit reached; writing partial image")
+	case errors.Is(err, context.Canceled):
+		log.Printf("interrupted; writing partial image")
+	}
+}
+
+// parseShutter parses a shutter speed in seconds, as a decimal or a fraction
+// like 1/250.
+func parseShutter(s string) (float64, error) {
+	num, den, isFraction := strings.Cut(s, "/")
+	t, err := strconv.ParseFloat(num, 64)
+	if err == nil && isFraction {
+		var d float64
+		if d, err = strconv.ParseFloat(den, 64); err == nil {
+			t /= d
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if t <= 0 || math.IsInf(t, 0) || math.IsNaN(t) {
+		return 0, fmt.Errorf("shutter speed %q is not positive", s)
+	}
+	return t, nil
+}
+
+// framePath returns the output file of an animation frame. pattern is a
+// printf format for the frame number, or a plain file name that the number
+// is inserted into before the extension.
+func framePath(pattern string, frame int) string {
+	if strings.Contains(pattern, "%") {
+		return fmt.Sprintf(pattern, frame)
+	}
+	ext := filepath.Ext(pattern)
+	return fmt.Sprintf("%s.%04d%s", strings.TrimSuffix(pattern, ext), frame, ext)
+}
+
+// aovPath returns the file an AOV is written to, next to the output file.
+func aovPath(output string, a render.AOV) string {
+	base := "aov"
+	if output != "" {
+		base = output[:len(output)-len(filepath.Ext(output))]
+	}
+	return fmt.Sprintf("%s.%s.pfm", base, a)
+}
+
+func writeAOV(path string, img *render.FloatImage) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := img.WritePFM(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writePPMHeader(output *os.File, width, height int) {
+	if _, err := fmt.Fprintln(output, "P3"); err != nil {
+		log.Fatalf("failed to write P3 header: %v", err)
+	}
+	if _, err := fmt.Fprintln(output, width, height); err != nil {
+		log.Fatalf("failed to write image dimensions: %v", err)
+	}
+	if _, err := fmt.Fprintln(output, "255"); err != nil {
+		log.Fatalf("failed to write max color value: %v", err)
+	}
+}
+
+// writeImage writes img, the pixels of r in the full frame, to output: on
+// its own, or pasted over the -composite image.
+func writeImage(output *os.File, img image.Image, r image.Rectangle) {
+	if compositeBase != nil {
+		out := image.NewRGBA(compositeBase.Bounds())
+		draw.Draw(out, out.Bounds(), compositeBase, image.Point{}, draw.Src)
+		draw.Draw(out, r, img, img.Bounds().Min, draw.Src)
+		img = out
+	}
+	if err := render.WritePPM(output, img); err != nil {
+		log.Printf("warning: failed to write image: %v", err)
+	}
+}
+
+// loadApertureMask reads an aperture mask image, a PPM or any format
+// registered with the image package.
+func loadApertureMask(path string) (*render.ApertureMask, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var img image.Image
+	if strings.EqualFold(filepath.Ext(path), ".ppm") {
+		img, err = render.ReadPPM(f)
+	} else {
+		img, _, err = image.Decode(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return render.NewApertureMask(img)
+}
+
+// loadComposite reads the -composite image, which must match the frame size.
+func loadComposite(path string, width, height int) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	img, err := render.ReadPPM(f)
+	if err != nil {
+		return nil, err
+	}
+	if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+		return nil, fmt.Errorf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), width, height)
+	}
+	return img, nil
+}
+
+// renderDistributed renders on the -workers processes and writes the merged
+// image to output. Tiles missing when ctx is done are left black.
+func renderDistributed(ctx context.Context, s render.RenderSettings, output *os.File, st *render.Stats) {
+	ctx, cancel := withTimeLimit(ctx)
+	defer cancel()
+
+	// only the image size and crop window of the camera are used here
+	cam, err := newCamera(s, scene.DefaultCamera, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var (
+		crop  = cam.Crop()
+		img   = render.NewFloatImage(crop.Width(), crop.Height(), 3)
+		bar   = progressbar.Default(int64(crop.Size()))
+		addrs = strings.Split(workers, ",")
+	)
+
+	start := time.Now()
+	err = render.RenderDistributed(ctx, s, cam.Tiles(tileSize), addrs, func(t render.Tile, reply render.TileReply) {
+		for k, c := range reply.Colors {
+			img.Set(t.X0-crop.X0+k%t.Width(), t.Y0-crop.Y0+k/t.Width(), c.X, c.Y, c.Z)
+		}
+		st.AddRays(reply.Stats)
+		if err := bar.Add(len(reply.Colors)); err != nil {
+			// progress bar errors are non-fatal; log and continue
+			log.Printf("warning: progress bar add failed: %v", err)
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Fatal("distributed render failed: ", err)
+	}
+	stopped(err)
+	st.Since("render", start)
+
+	start = time.Now()
+	writeImage(output, img.RGBA(), cam.CropRect())
+	st.Since("encode", start)
+}
+
+// serveWorker renders tiles for coordinators until the process is killed.
+func serveWorker() {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal("could not listen: ", err)
+	}
+	log.Printf("worker listening on %s", l.Addr())
+	if err := render.ServeWorker(l, render.NewWorker(sceneBuilder(nil))); err != nil {
+		log.Fatal("worker failed: ", err)
+	}
+}
+
+// renderProgressive renders in passes, checkpointing if requested and, in
+// serve mode, serving a live preview over HTTP. The image is written to
+// output when finished, out of time or ctx is done; in serve mode the preview
+// then stays up until ctx is done.
+func renderProgressive(ctx context.Context, s render.RenderSettings, cam render.Camera, world *geometry.Hittables, output *os.File, cp *render.Checkpoint, serve bool, st *render.Stats) {
+	var (
+		bar  = progressbar.Default(int64(cam.Crop().Size() * cam.Samples()))
+		opts []render.ProgressiveOpt
+	)
+	if checkpoint != "" {
+		opts = append(opts, render.CheckpointTo(checkpoint, cpEvery, s))
+	}
+	p := render.NewProgressive(cam, world, passSize, bar, opts...)
+	if cp != nil {
+		if err := p.Restore(*cp); err != nil {
+			log.Fatal("could not resume from checkpoint: ", err)
+		}
+	}
+
+	var srv *http.Server
+	if serve {
+		srv = &http.Server{Addr: addr, Handler: p.ServeMux()}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal("preview server failed: ", err)
+			}
+		}()
+		log.Printf("serving preview on http://%s/", addr)
+	}
+
+	renderCtx, cancel := withTimeLimit(ctx)
+	defer cancel()
+
+	start := time.Now()
+	if err := p.RunContext(renderCtx); err != nil {
+		if renderCtx.Err() == nil {
+			log.Fatal("render failed: ", err)
+		}
+		stopped(err)
+		log.Printf("rendered %d samples per pixel", p.Samples())
+	}
+	st.Since("render", start)
+	st.AddRays(p.RayStats())
+
+	start = time.Now()
+	writeImage(output, p.Image().SubImage(cam.CropRect()), cam.CropRect())
+	st.Since("encode", start)
+
+	if srv == nil || ctx.Err() != nil {
+		return
+	}
+	log.Printf("render complete; still serving on http://%s/, interrupt to exit", addr)
+	<-ctx.Done()
+	if err := srv.Shutdown(context.Background()); err != nil {
+		log.Printf("warning: failed to shut down preview server: %v", err)
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	// modes may appear before or after the flags
+	mode := flag.Arg(0)
+	switch mode {
+	case "":
+	case "serve", "worker":
+		if err := flag.CommandLine.Parse(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown mode %q", mode)
+	}
+	serveMode := mode == "serve"
+
+	if mode == "worker" {
+		serveWorker()
+		return
+	}
+
+	aovs, err := render.ParseAOVs(aovList)
+	if err != nil {
+		log.Fatal("invalid -aov: ", err)
+	}
+	if sceneFile != "" && !isFlagSet("projection") {
+		f, err := scenes.load(sceneFile)
+		if err != nil {
+			log.Fatal("could not load scene: ", err)
+		}
+		if f.Projection != "" {
+			projection = f.Projection
+		}
+	}
+	if projType, err = render.ParseProjection(projection); err != nil {
+		log.Fatal("invalid projection: ", err)
+	}
+	if stereoLayout, err = render.ParseStereoLayout(stereo); err != nil {
+		log.Fatal("invalid -stereo: ", err)
+	}
+	if shutter != "" {
+		if shutterSpeed, err = parseShutter(shutter); err != nil {
+			log.Fatal("invalid -shutter: ", err)
+		}
+	}
+	if cropWindow != "" {
+		if cropOpt, err = render.ParseCrop(cropWindow); err != nil {
+			log.Fatal("invalid -crop: ", err)
+		}
+	}
+	if composite != "" {
+		if compositeBase, err = loadComposite(composite, imgWidth, imgHeight); err != nil {
+			log.Fatal("could not load -composite image: ", err)
+		}
+	}
+
+	// configure profiling
+
+	if cpuprofile != "" {
+		f, err := os.Create(cpuprofile)
+		if err != nil {
+			log.Fatal("could not create CPU profile: ", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Printf("warning: failed to close cpu profile file: %v", err)
+			}
+		}()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal("could not start CPU profile: ", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	// scene
+
+	var cp *render.Checkpoint
+	if resume != "" {
+		c, err := render.LoadCheckpoint(resume)
+		if err != nil {
+			log.Fatal("could not load checkpoint: ", err)
+		}
+		cp = &c
+		if sceneSeed == 0 {
+			// resume the random scene; other settings must match, see Restore
+			sceneSeed = c.Settings.Seed
+		}
+		if checkpoint == "" {
+			checkpoint = resume
+		}
+	}
+	if sceneSeed == 0 {
+		sceneSeed = rand.Int63()
+	}
+
+	// an interrupt stops rendering, and what was rendered is still written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var st *render.Stats
+	switch statsFmt {
+	case "":
+	case "summary", "json":
+		st = &render.Stats{}
+		defer writeStats(st)
+	default:
+		log.Fatalf("unknown -stats format %q", statsFmt)
+	}
+
+	if frames == "" {
+		renderImage(ctx, settings(), outputFile, aovs, cp, serveMode, st)
+		return
+	}
+
+	// animation
+
+	if serveMode || checkpoint != "" {
+		log.Fatal("serve mode and checkpoints render a single frame; they cannot be used with -frames")
+	}
+	if outputFile == "" {
+		log.Fatal("-frames needs an -output file name")
+	}
+	first, last, err := anim.ParseFrames(frames)
+	if err != nil {
+		log.Fatal("invalid -frames: ", err)
+	}
+	for frame := first; frame <= last; frame++ {
+		s := settings()
+		s.Frame = frame
+		path := framePath(outputFile, frame)
+		log.Printf("rendering frame %d to %s", frame, path)
+		renderImage(ctx, s, path, aovs, nil, false, st)
+		if ctx.Err() != nil {
+			log.Printf("interrupted; stopping after frame %d", frame)
+			break
+		}
+	}
+}
+
+// renderImage renders the frame described by s to path, or to stdout if path
+// is empty, along with its AOVs. Statistics are added to st, if non-nil. If
+// ctx is done before the frame is, the partial frame is written.
+func renderImage(ctx context.Context, s render.RenderSettings, path string, aovs []render.AOV, cp *render.Checkpoint, serveMode bool, st *render.Stats) {
+	if (serveMode || checkpoint != "" || timeLimit > 0) && workers == "" && (len(aovs) > 0 || denoise) {
+		log.Fatal("-aov and -denoise are not supported in serve mode or with -checkpoint or -time-limit")
+	}
+
+	// setup output
+
+	output := os.Stdout
+	if path != "" {
+		var err error
+		output, err = os.Create(path)
+		if err != nil {
+			log.Fatal("could not create output file: ", err)
+		}
+		defer func() {
+			if err := output.Close(); err != nil {
+				log.Printf("warning: failed to close output file: %v", err)
+			}
+		}()
+	}
+
+	if workers != "" {
+		if serveMode || checkpoint != "" || len(aovs) > 0 || denoise {
+			log.Printf("warning: serve, checkpoint, -aov and -denoise are not supported with -workers")
+		}
+		renderDistributed(ctx, s, output, st)
+		return
+	}
+
+	// output image
+
+	cam, world, err := sceneBuilder(st)(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a time limit needs passes, to stop with the same samples everywhere
+	if serveMode || checkpoint != "" || timeLimit > 0 {
+		renderProgressive(ctx, s, cam, world, output, cp, serveMode, st)
+		return
+	}
+
+	// images cover the crop window, by default the whole frame
+	crop := cam.Crop()
+
+	aovImages := make(map[render.AOV]*render.FloatImage, len(aovs))
+	for _, a := range aovs {
+		aovImages[a] = render.NewFloatImage(crop.Width(), crop.Height(), a.Channels())
+	}
+
+	// denoising needs the whole frame and its guide AOVs before any output,
+	// and compositing needs the whole frame
+	var beauty, albedo, normal *render.FloatImage
+	if denoise || compositeBase != nil {
+		beauty = render.NewFloatImage(crop.Width(), crop.Height(), 3)
+		albedo = render.NewFloatImage(crop.Width(), crop.Height(), 3)
+		normal = render.NewFloatImage(crop.Width(), crop.Height(), 3)
+	} else {
+		writePPMHeader(output, crop.Width(), crop.Height())
+	}
+
+	var (
+		bar     = progressbar.Default(int64(crop.Size()))
+		start   = time.Now()
+		encode  time.Duration
+		written int
+	)
+	for pixel := range cam.RenderPixelsContext(ctx, world) {
+		i, j := pixel.I-crop.X0, pixel.J-crop.Y0
+		if beauty != nil {
+			c := pixel.Color
+			beauty.Set(i, j, c.X, c.Y, c.Z)
+			albedo.Set(i, j, pixel.AOV.Value(render.AOVAlbedo)...)
+			normal.Set(i, j, pixel.AOV.Value(render.AOVNormal)...)
+		} else {
+			t := time.Now()
+			rgb := pixel.Color.RGB(1)
+			if _, err := fmt.Fprintln(output, rgb.R, rgb.G, rgb.B); err != nil {
+				log.Printf("warning: failed to write pixel: %v", err)
+			}
+			written++
+			encode += time.Since(t)
+		}
+		for a, img := range aovImages {
+			img.Set(i, j, pixel.AOV.Value(a)...)
+		}
+		st.AddRays(pixel.Stats)
+		if err := bar.Add(1); err != nil {
+			// progress bar errors are non-fatal; log and continue
+			log.Printf("warning: progress bar add failed: %v", err)
+		}
+	}
+
+	// pixels are streamed out as they finish, so output is not part of render
+	st.AddPhase("render", time.Since(start)-encode)
+	st.AddPhase("encode", encode)
+
+	// pixels stream out top row first, so pad the rows not reached
+	stopped(ctx.Err())
+	if beauty == nil {
+		for ; written < crop.Size(); written++ {
+			if _, err := fmt.Fprintln(output, 0, 0, 0); err != nil {
+				log.Printf("warning: failed to write pixel: %v", err)
+				break
+			}
+		}
+	}
+
+	if denoise {
+		start = time.Now()
+		beauty = render.Denoise(beauty, albedo, normal, render.DenoiseJobs(runtime.NumCPU()))
+		st.Since("denoise", start)
+	}
+	if beauty != nil {
+		start = time.Now()
+		writeImage(output, beauty.RGBA(), cam.CropRect())
+		st.Since("encode", start)
+	}
+
+	start = time.Now()
+	for a, img := range aovImages {
+		if err := writeAOV(aovPath(path, a), img); err != nil {
+			log.Printf("warning: failed to write %s AOV: %v", a, err)
+		}
+	}
+	st.Since("encode", start)
+}
+
+// writeStats writes st to -statsfile, or stderr, in the -stats format.
+func writeStats(st *render.Stats) {
+	w := os.Stderr
+	if statsFile != "" {
+		f, err := os.Create(statsFile)
+		if err != nil {
+			log.Printf("warning: could not create stats file: %v", err)
+			return
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Printf("warning: failed to close stats file: %v", err)
+			}
+		}()
+		w = f
+	}
+
+	var err error
+	if statsFmt == "json" {
+		err = st.WriteJSON(w)
+	} else {
+		err = st.WriteSummary(w)
+	}
+	if err != nil {
+		log.Printf("warning: failed to write stats: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// AABB is an axis-aligned bounding box defined by two corner points.
+type AABB struct {
+	Min, Max vecmath.Point3
+}
+
+func (b AABB) Hit(r Ray, tmin, tmax float64) bool {
+	// Slab method: check overlap of ray intervals on each axis.
+	invD := 1.0 / r.Dir.X
+	t0 := (b.Min.X - r.Orig.X) * invD
+	t1 := (b.Max.X - r.Orig.X) * invD
+	if invD < 0 {
+		t0, t1 = t1, t0
+	}
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	if tmax <= tmin {
+		return false
+	}
+
+	invD = 1.0 / r.Dir.Y
+	t0 = (b.Min.Y - r.Orig.Y) * invD
+	t1 = (b.Max.Y - r.Orig.Y) * invD
+	if invD < 0 {
+		t0, t1 = t1, t0
+	}
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	if tmax <= tmin {
+		return false
+	}
+
+	invD = 1.0 / r.Dir.Z
+	t0 = (b.Min.Z - r.Orig.Z) * invD
+	t1 = (b.Max.Z - r.Orig.Z) * invD
+	if invD < 0 {
+		t0, t1 = t1, t0
+	}
+	if t0 > tmin {
+		tmin = t0
+	}
+	if t1 < tmax {
+		tmax = t1
+	}
+	if tmax <= tmin {
+		return false
+	}
+
+	return true
+}
+
+// SurroundingBox returns the AABB that encloses both input boxes.
+func SurroundingBox(a, b AABB) AABB {
+	return AABB{
+		Min: vecmath.Vec3{
+			X: math.Min(a.Min.X, b.Min.X),
+			Y: math.Min(a.Min.Y, b.Min.Y),
+			Z: math.Min(a.Min.Z, b.Min.Z),
+		},
+		Max: vecmath.Vec3{
+			X: math.Max(a.Max.X, b.Max.X),
+			Y: math.Max(a.Max.Y, b.Max.Y),
+			Z: math.Max(a.Max.Z, b.Max.Z),
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package geometry
+
+import (
+	"github.com/mhv2109/RayTracing/texture"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ Hittable = BumpMapped{}
+	_ Hittable = NormalMapped{}
+)
+
+// bumpDelta is the step in surface coordinates over which BumpMapped
+// differentiates its heights.
+const bumpDelta = 1e-4
+
+// BumpMapped shades a Hittable as if its surface were raised along the
+// normal by Scale times Height, without moving the surface itself. The
+// Hittable must fill in HitRecord's surface coordinates and tangents, as
+// Sphere does.
+type BumpMapped struct {
+	Hittable
+	Height texture.Scalar
+	Scale  float64
+}
+
+func (b BumpMapped) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	if !b.Hittable.Hit(r, tmin, tmax, hr) {
+		return false
+	}
+
+	var (
+		n    = hr.outward()
+		h    = b.Height.Value(hr.U, hr.V, hr.P)
+		hu   = b.Height.Value(hr.U+bumpDelta, hr.V, hr.P.Add(hr.Tangent.MulS(bumpDelta)))
+		hv   = b.Height.Value(hr.U, hr.V+bumpDelta, hr.P.Add(hr.Bitangent.MulS(bumpDelta)))
+		dpdu = hr.Tangent.Add(n.MulS(b.Scale * (hu - h) / bumpDelta))
+		dpdv = hr.Bitangent.Add(n.MulS(b.Scale * (hv - h) / bumpDelta))
+		bn   = dpdu.Cross(dpdv)
+	)
+	if bn.Dot(n) < 0 {
+		bn = bn.Neg()
+	}
+	hr.shade(r, bn)
+	return true
+}
+
+// NormalMapped shades a Hittable with normals read from a tangent-space
+// normal map: red, green and blue map from [0, 1] to [-1, 1] along the
+// surface's tangent, bitangent and normal, the OpenGL convention. The
+// Hittable must fill in HitRecord's surface coordinates and tangents, as
+// Sphere does.
+type NormalMapped struct {
+	Hittable
+	Map *texture.Image
+}
+
+func (m NormalMapped) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	if !m.Hittable.Hit(r, tmin, tmax, hr) {
+		return false
+	}
+
+	var (
+		c = m.Map.Color(hr.U, hr.V).MulS(2).SubS(1)
+		n = hr.outward()
+		t = hr.Tangent.Sub(n.MulS(n.Dot(hr.Tangent)))
+	)
+	if t.NearZero() {
+		return true
+	}
+	t = t.Unit()
+	b := n.Cross(t)
+	if b.Dot(hr.Bitangent) < 0 {
+		b = b.Neg()
+	}
+	hr.shade(r, t.MulS(c.X).Add(b.MulS(c.Y)).Add(n.MulS(c.Z)))
+	return true
+}
+
+// outward returns the surface normal pointing out of the object, which
+// NewHitRecord flips towards the ray for back faces.
+func (hr *HitRecord) outward() vecmath.Vec3 {
+	if hr.F {
+		return hr.N
+	}
+	return hr.N.Neg()
+}
+
+// shade replaces hr.N with the outward shading normal n, flipped towards the
+// ray like NewHitRecord does. Normals that would face away from the ray are
+// ignored, keeping the geometric normal, since materials expect the viewer
+// above the surface.
+func (hr *HitRecord) shade(r Ray, n vecmath.Vec3) {
+	if n.NearZero() {
+		return
+	}
+	if !hr.F {
+		n = n.Neg()
+	}
+	if r.Dir.Dot(n) >= 0 {
+		return
+	}
+	hr.N = n.Unit()
+}
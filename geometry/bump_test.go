@@ -0,0 +1,103 @@
+package geometry
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/texture"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// hitFront hits the front of a unit sphere at (0, 0, -3) from the origin,
+// where u = 0.25, v = 0.5, the tangent points along +X and the bitangent +Y.
+func hitFront(t *testing.T, obj Hittable) HitRecord {
+	t.Helper()
+	var hr HitRecord
+	if !obj.Hit(Ray{Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}, 1e-3, math.MaxFloat64, &hr) {
+		t.Fatalf("missed the sphere")
+	}
+	return hr
+}
+
+var bumpSphere = Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -3}, R: 1}
+
+func TestSphereUV(t *testing.T) {
+	hr := hitFront(t, bumpSphere)
+	if !almostEqual(hr.U, 0.25) || !almostEqual(hr.V, 0.5) {
+		t.Errorf("uv = %v, %v, want 0.25, 0.5", hr.U, hr.V)
+	}
+	if !vecAlmostEqual(hr.Tangent, vecmath.Vec3{X: 2 * math.Pi, Y: 0, Z: 0}) || !vecAlmostEqual(hr.Bitangent, vecmath.Vec3{X: 0, Y: math.Pi, Z: 0}) {
+		t.Errorf("tangents = %v, %v", hr.Tangent, hr.Bitangent)
+	}
+
+	// the derivatives match finite differences away from the front too
+	point := func(u, v float64) vecmath.Point3 {
+		sinT, cosT := math.Sincos(v * math.Pi)
+		sinP, cosP := math.Sincos(u * 2 * math.Pi)
+		return vecmath.Point3{X: -sinT * cosP, Y: -cosT, Z: sinT * sinP}.MulS(2)
+	}
+	const u, v, e = 0.6, 0.3, 1e-7
+	uu, vv, dpdu, dpdv := sphereUV(point(u, v).DivS(2), 2)
+	if !almostEqual(uu, u) || !almostEqual(vv, v) {
+		t.Errorf("sphereUV = %v, %v, want %v, %v", uu, vv, u, v)
+	}
+	if d := point(u+e, v).Sub(point(u, v)).DivS(e); d.Sub(dpdu).Len() > 1e-5 {
+		t.Errorf("dpdu = %v, want %v", dpdu, d)
+	}
+	if d := point(u, v+e).Sub(point(u, v)).DivS(e); d.Sub(dpdv).Len() > 1e-5 {
+		t.Errorf("dpdv = %v, want %v", dpdv, d)
+	}
+
+	// instances carry the tangents along: a quarter turn about Z turns +X
+	// into +Y
+	inst := NewInstance(bumpSphere, Transform{Rotate: vecmath.Vec3{X: 0, Y: 0, Z: 90}, Scale: vecmath.Vec3{X: 1, Y: 1, Z: 1}})
+	if tangent := hitFront(t, inst).Tangent; !vecAlmostEqual(tangent, vecmath.Vec3{X: 0, Y: 2 * math.Pi, Z: 0}) {
+		t.Errorf("rotated instance tangent = %v, want +Y", tangent)
+	}
+}
+
+func TestBumpMapped(t *testing.T) {
+	flat := BumpMapped{bumpSphere, texture.ScalarFunc(func(u, v float64, p vecmath.Point3) float64 { return 1 }), 1}
+	if n := hitFront(t, flat).N; !vecAlmostEqual(n, vecmath.Vec3{X: 0, Y: 0, Z: 1}) {
+		t.Errorf("constant height normal = %v, want +Z", n)
+	}
+
+	// rising towards +X, as fast as the surface runs along it, tilts the
+	// normal 45 degrees back
+	ramp := BumpMapped{bumpSphere, texture.ScalarFunc(func(u, v float64, p vecmath.Point3) float64 { return u }), 2 * math.Pi}
+	hr := hitFront(t, ramp)
+	if want := (vecmath.Vec3{X: -1, Y: 0, Z: 1}).Unit(); hr.N.Sub(want).Len() > 1e-3 {
+		t.Errorf("ramp normal = %v, want %v", hr.N, want)
+	}
+	if hr.P != hitFront(t, bumpSphere).P {
+		t.Errorf("bump mapping moved the hit point")
+	}
+}
+
+func TestNormalMapped(t *testing.T) {
+	normalMap := func(c color.NRGBA64) *texture.Image {
+		img := image.NewNRGBA64(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA64(0, 0, c)
+		return texture.NewImage(img)
+	}
+
+	flat := NormalMapped{bumpSphere, normalMap(color.NRGBA64{R: 0x8000, G: 0x8000, B: 0xffff, A: 0xffff})}
+	if n := hitFront(t, flat).N; n.Sub(vecmath.Vec3{X: 0, Y: 0, Z: 1}).Len() > 1e-3 {
+		t.Errorf("flat normal map normal = %v, want +Z", n)
+	}
+
+	// (0.6, 0, 0.8) in tangent space leans towards the tangent, +X
+	tilted := NormalMapped{bumpSphere, normalMap(color.NRGBA64{R: 0xcccc, G: 0x8000, B: 0xe666, A: 0xffff})}
+	if n := hitFront(t, tilted).N; n.Sub(vecmath.Vec3{X: 0.6, Y: 0, Z: 0.8}).Len() > 1e-3 {
+		t.Errorf("tilted normal map normal = %v, want (0.6, 0, 0.8)", n)
+	}
+
+	// pointing sideways would face away from the ray, so the geometric
+	// normal stays
+	sideways := NormalMapped{bumpSphere, normalMap(color.NRGBA64{R: 0xffff, G: 0x8000, B: 0x4000, A: 0xffff})}
+	if n := hitFront(t, sideways).N; !vecAlmostEqual(n, vecmath.Vec3{X: 0, Y: 0, Z: 1}) {
+		t.Errorf("backfacing normal map normal = %v, want +Z", n)
+	}
+}
@@ -0,0 +1,228 @@
+// Package geometry defines rays, the Hittable and Material interfaces that
+// shapes and surfaces implement, and the built-in shapes and transforms.
+package geometry
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// HitRecord captures the requisite details of a Ray intersecting with a Hittable.
+type HitRecord struct {
+	// Exact point of impact
+	P vecmath.Point3
+
+	// Surface-normal vector
+	N vecmath.Vec3
+
+	// Surface coordinates of P, from 0 to 1, and the derivatives of P along
+	// them, ∂P/∂u and ∂P/∂v; zero for shapes without a parameterization
+	U, V               float64
+	Tangent, Bitangent vecmath.Vec3
+
+	// Parameter t of impact
+	T float64
+
+	// "front" facing?
+	F bool
+
+	// Material of impacted object
+	M Material
+
+	// Wavelength in nm carried by the path in spectral mode, 0 otherwise
+	Lambda float64
+
+	// ID of the impacted object, if tagged with Identified
+	ID int
+
+	// Random numbers of the path that hit, see Ray.Rand
+	Rand *vecmath.Rand
+}
+
+func NewHitRecord(P vecmath.Point3, N vecmath.Vec3, T float64, M Material, r Ray) HitRecord {
+	hr := HitRecord{P: P, N: N, T: T, F: false, M: M, Rand: r.Rand}
+
+	hr.F = r.Dir.Dot(N) < 0
+	if !hr.F {
+		hr.N = hr.N.Neg()
+	}
+
+	return hr
+}
+
+type Hittable interface {
+	// Hit checks if r interesects with Hittable. If so, it returns a HitRecord, nil otherwise.
+	Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool
+
+	// BoundingBox returns the axis-aligned bounding box enclosing the hittable.
+	BoundingBox() AABB
+}
+
+// Sphere is a shape defined by a Center point and a radius.
+type Sphere struct {
+	Center vecmath.Point3
+	R      float64
+	M      Material
+}
+
+func (s Sphere) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	r.Stats.AddPrimitiveTest()
+
+	// A ray intersects the sphere if there exists two solutions for the quadratic
+	// equation (P(t) - C) dot (P(t) - C) - r^2 = 0 for all t, where P(t) = A + t*halfb.
+	// We can determine this by calulating the descriminant d. This has been
+	// simplified using the method in section 6.2.
+	var (
+		oc = r.Orig.Sub(s.Center) // A - C
+
+		a     = r.Dir.LenSq()
+		halfb = oc.Dot(r.Dir)
+		c     = oc.LenSq() - s.R*s.R
+
+		d = halfb*halfb - a*c
+	)
+
+	if d < 0 {
+		return false
+	}
+
+	// Find the nearest root that lies in the acceptable range, and is not
+	// masked out.
+	sqrtd := math.Sqrt(d)
+	for _, root := range [2]float64{(-halfb - sqrtd) / a, (-halfb + sqrtd) / a} {
+		if root < tmin || tmax < root {
+			continue
+		}
+
+		var (
+			T    = root
+			P    = r.At(T)
+			N    = P.Sub(s.Center).DivS(s.R)
+			temp = NewHitRecord(P, N, T, s.M, r)
+		)
+		temp.U, temp.V, temp.Tangent, temp.Bitangent = sphereUV(N, s.R)
+		if !opaque(temp) {
+			continue
+		}
+		*hr = temp
+		return true
+	}
+	return false
+}
+
+// sphereUV returns the surface coordinates and their derivatives on a sphere
+// of radius r at outward normal n: u is the longitude, from -X around
+// through +Z, and v the latitude, from the south pole at -Y to the north.
+func sphereUV(n vecmath.Vec3, r float64) (u, v float64, dpdu, dpdv vecmath.Vec3) {
+	var (
+		theta = math.Acos(math.Max(-1, math.Min(1, -n.Y)))
+		phi   = math.Atan2(-n.Z, n.X) + math.Pi
+		sin   = math.Max(math.Sin(theta), 1e-9) // at the poles
+	)
+	u, v = phi/(2*math.Pi), theta/math.Pi
+	dpdu = vecmath.Vec3{X: n.Z, Y: 0, Z: -n.X}.MulS(2 * math.Pi * r)
+	dpdv = vecmath.Vec3{X: -n.X * n.Y / sin, Y: sin, Z: -n.Y * n.Z / sin}.MulS(math.Pi * r)
+	return u, v, dpdu, dpdv
+}
+
+func (s Sphere) BoundingBox() AABB {
+	offset := vecmath.Vec3{X: s.R, Y: s.R, Z: s.R}
+	return AABB{
+		Min: s.Center.Sub(offset),
+		Max: s.Center.Add(offset),
+	}
+}
+
+type Hittables struct {
+	Objects []Hittable
+}
+
+func NewHittables(objects ...Hittable) Hittables {
+	o := make([]Hittable, len(objects))
+	copy(o, objects)
+	return Hittables{Objects: o}
+}
+
+func (h *Hittables) Add(objects ...Hittable) {
+	h.Objects = append(h.Objects, objects...)
+}
+
+func (h *Hittables) Clear() {
+	for i := range h.Objects {
+		h.Objects[i] = nil
+	}
+	h.Objects = h.Objects[:0]
+}
+
+func (h *Hittables) BoundingBox() AABB {
+	if len(h.Objects) == 0 {
+		return AABB{}
+	}
+	box := h.Objects[0].BoundingBox()
+	for _, obj := range h.Objects[1:] {
+		box = SurroundingBox(box, obj.BoundingBox())
+	}
+	return box
+}
+
+func (h *Hittables) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	var (
+		hit     = false
+		closest = tmax
+	)
+
+	for _, object := range h.Objects {
+		if object.Hit(r, tmin, closest, hr) {
+			closest = hr.T
+			hit = true
+		}
+	}
+
+	return hit
+}
+
+// Identified tags a Hittable with an ID that is reported in HitRecord.ID.
+type Identified struct {
+	Hittable
+	ID int
+}
+
+func (o Identified) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	if !o.Hittable.Hit(r, tmin, tmax, hr) {
+		return false
+	}
+	hr.ID = o.ID
+	return true
+}
+
+// Named tags a Hittable with a name, so that it can be found in a scene, for
+// example to focus on.
+type Named struct {
+	Hittable
+	Name string
+}
+
+// FindNamed returns the object called name among objects, looking through
+// Identified tags.
+func FindNamed(objects []Hittable, name string) (Hittable, bool) {
+	for _, obj := range objects {
+		if id, ok := obj.(Identified); ok {
+			obj = id.Hittable
+		}
+		if n, ok := obj.(Named); ok && n.Name == name {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Identify wraps each object in an Identified with IDs counting from 1, so
+// that 0 can mean "no object".
+func Identify(objects []Hittable) []Hittable {
+	out := make([]Hittable, len(objects))
+	for i, obj := range objects {
+		out[i] = Identified{obj, i + 1}
+	}
+	return out
+}
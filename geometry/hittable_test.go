@@ -1,8 +1,10 @@
-package main
+package geometry
 
 import (
 	"math"
 	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
 )
 
 const floatEps = 1e-6
@@ -11,14 +13,14 @@ func almostEqual(a, b float64) bool {
 	return math.Abs(a-b) < floatEps
 }
 
-func vecAlmostEqual(a, b Vec3) bool {
+func vecAlmostEqual(a, b vecmath.Vec3) bool {
 	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
 }
 
 func TestHittablesHitEmpty(t *testing.T) {
 	var world Hittables
 
-	ray := Ray{Orig: Point3{0, 0, 0}, Dir: Vec3{1, 0, 0}}
+	ray := Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 1, Y: 0, Z: 0}}
 	var hr HitRecord
 
 	if hit := world.Hit(ray, 0.001, math.MaxFloat64, &hr); hit {
@@ -27,8 +29,8 @@ func TestHittablesHitEmpty(t *testing.T) {
 }
 
 func TestSphereSingleHit(t *testing.T) {
-	sphere := Sphere{Center: Point3{0, 0, -1}, R: 0.5}
-	ray := Ray{Orig: Point3{0, 0, 0}, Dir: Vec3{0, 0, -1}}
+	sphere := Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5}
+	ray := Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}
 
 	var hr HitRecord
 	if !sphere.Hit(ray, 0.001, math.MaxFloat64, &hr) {
@@ -41,24 +43,24 @@ func TestSphereSingleHit(t *testing.T) {
 		t.Fatalf("hr.T = %v, want ~0.5", hr.T)
 	}
 
-	wantP := Point3{0, 0, -0.5}
-	if !vecAlmostEqual(Vec3(hr.P), Vec3(wantP)) {
+	wantP := vecmath.Point3{X: 0, Y: 0, Z: -0.5}
+	if !vecAlmostEqual(vecmath.Vec3(hr.P), vecmath.Vec3(wantP)) {
 		t.Fatalf("hit point = %#v, want %#v", hr.P, wantP)
 	}
 
-	wantN := Vec3{0, 0, 1}
+	wantN := vecmath.Vec3{X: 0, Y: 0, Z: 1}
 	if !vecAlmostEqual(hr.N, wantN) {
 		t.Fatalf("normal = %#v, want %#v", hr.N, wantN)
 	}
 }
 
 func TestHittablesHitChoosesNearest(t *testing.T) {
-	near := Sphere{Center: Point3{0, 0, -1}, R: 0.5}
-	far := Sphere{Center: Point3{0, 0, -3}, R: 0.5}
+	near := Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5}
+	far := Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -3}, R: 0.5}
 
 	world := NewHittables(near, far)
 	var hr HitRecord
-	ray := Ray{Orig: Point3{0, 0, 0}, Dir: Vec3{0, 0, -1}}
+	ray := Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}
 
 	if !world.Hit(ray, 0.001, math.MaxFloat64, &hr) {
 		t.Fatalf("expected ray to hit world")
@@ -69,16 +71,16 @@ func TestHittablesHitChoosesNearest(t *testing.T) {
 		t.Fatalf("nearest hit T = %v, want ~0.5", hr.T)
 	}
 
-	wantP := Point3{0, 0, -0.5}
-	if !vecAlmostEqual(Vec3(hr.P), Vec3(wantP)) {
+	wantP := vecmath.Point3{X: 0, Y: 0, Z: -0.5}
+	if !vecAlmostEqual(vecmath.Vec3(hr.P), vecmath.Vec3(wantP)) {
 		t.Fatalf("hit point = %#v, want %#v", hr.P, wantP)
 	}
 }
 
 func TestNewHitRecordFrontFace(t *testing.T) {
-	P := Point3{0, 0, -1}
-	N := Vec3{0, 0, 1}
-	ray := Ray{Orig: Point3{0, 0, 0}, Dir: Vec3{0, 0, -1}}
+	P := vecmath.Point3{X: 0, Y: 0, Z: -1}
+	N := vecmath.Vec3{X: 0, Y: 0, Z: 1}
+	ray := Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}
 
 	hr := NewHitRecord(P, N, 1.0, nil, ray)
 
@@ -92,10 +94,10 @@ func TestNewHitRecordFrontFace(t *testing.T) {
 }
 
 func TestNewHitRecordBackFace(t *testing.T) {
-	P := Point3{0, 0, -1}
-	N := Vec3{0, 0, 1}
+	P := vecmath.Point3{X: 0, Y: 0, Z: -1}
+	N := vecmath.Vec3{X: 0, Y: 0, Z: 1}
 	// Ray going in same direction as normal means we are inside the surface
-	ray := Ray{Orig: Point3{0, 0, -1}, Dir: Vec3{0, 0, 1}}
+	ray := Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: -1}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: 1}}
 
 	hr := NewHitRecord(P, N, 1.0, nil, ray)
 
@@ -108,3 +110,17 @@ func TestNewHitRecordBackFace(t *testing.T) {
 		t.Fatalf("normal = %#v, want %#v", hr.N, wantN)
 	}
 }
+
+func TestFindNamed(t *testing.T) {
+	var (
+		a       = Named{Sphere{Center: vecmath.Point3{X: 1}, R: 1}, "a"}
+		b       = Named{Sphere{Center: vecmath.Point3{X: 2}, R: 1}, "b"}
+		objects = Identify([]Hittable{Sphere{R: 1}, a, b})
+	)
+	if obj, ok := FindNamed(objects, "b"); !ok || obj != Hittable(b) {
+		t.Errorf("FindNamed(b) = %v, %v", obj, ok)
+	}
+	if _, ok := FindNamed(objects, "c"); ok {
+		t.Errorf("FindNamed found a missing name")
+	}
+}
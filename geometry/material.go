@@ -0,0 +1,105 @@
+package geometry
+
+import (
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Material describes object + ray interactions as a BSDF. Directions are unit
+// vectors pointing away from the surface: wo towards the viewer, wi towards
+// the next bounce.
+type Material interface {
+	// Sample draws an incoming direction wi for wo and fills bs. It returns
+	// false if the path is absorbed.
+	Sample(wo vecmath.Vec3, hr HitRecord, bs *BSDFSample) bool
+
+	// Eval returns the BSDF value f(wo, wi), without the cosine term. Delta
+	// (perfectly specular) lobes always evaluate to black.
+	Eval(wo, wi vecmath.Vec3, hr HitRecord) vecmath.Color
+
+	// PDF returns the solid-angle density of Sample choosing wi for wo. Delta
+	// lobes always have a density of 0.
+	PDF(wo, wi vecmath.Vec3, hr HitRecord) float64
+}
+
+// BSDFSample is the result of Material.Sample.
+type BSDFSample struct {
+	// Sampled incoming direction
+	Wi vecmath.Vec3
+
+	// Path throughput weight, f(wo, wi) * |cos(wi)| / PDF
+	Weight vecmath.Color
+
+	// Solid-angle density of Wi, or 0 for delta lobes
+	PDF float64
+
+	// Wi was drawn from a delta distribution
+	Specular bool
+}
+
+// Albedoer is implemented by materials that can report a representative
+// reflectance, used for the albedo AOV.
+type Albedoer interface {
+	Albedo() vecmath.Color
+}
+
+// Masker is implemented by materials with an alpha mask, such as leaves cut
+// out of a quad. Hittables ignore intersections that Opaque rejects, so rays
+// pass through those parts of the surface as if it were not there.
+type Masker interface {
+	Opaque(hr HitRecord) bool
+}
+
+// opaque reports whether the surface hit in hr is there, according to its
+// material's mask if it has one.
+func opaque(hr HitRecord) bool {
+	m, ok := hr.M.(Masker)
+	return !ok || m.Opaque(hr)
+}
+
+// Medium is implemented by materials with a scattering interior, such as
+// skin, wax or marble. The renderer random walks paths that refract into the
+// surface through the interior until they leave it again.
+type Medium interface {
+	// Coefficients returns the interior's scattering and absorption
+	// coefficients, per unit distance.
+	Coefficients() (scattering, absorption vecmath.Color)
+
+	// Phase draws, from rng, the direction a path traveling along the unit
+	// vector dir continues in after scattering in the interior.
+	Phase(dir vecmath.Vec3, rng *vecmath.Rand) vecmath.Vec3
+}
+
+// Interior is implemented by materials that wrap another, such as a cutout
+// mask or a coat, to report the Medium of the material they wrap, if any.
+type Interior interface {
+	Interior() (Medium, bool)
+}
+
+// MediumOf returns the Medium of m, or of the material m wraps, if it has
+// one.
+func MediumOf(m Material) (Medium, bool) {
+	switch m := m.(type) {
+	case Medium:
+		return m, true
+	case Interior:
+		return m.Interior()
+	}
+	return nil, false
+}
+
+// ScatteringAlbedo returns the fraction of extinction, scattering plus
+// absorption, that is scattering, per channel. Channels without either are
+// transparent, with an albedo of 0.
+func ScatteringAlbedo(scattering, absorption vecmath.Color) vecmath.Color {
+	ratio := func(s, a float64) float64 {
+		if s+a == 0 {
+			return 0
+		}
+		return s / (s + a)
+	}
+	return vecmath.Color{
+		X: ratio(scattering.X, absorption.X),
+		Y: ratio(scattering.Y, absorption.Y),
+		Z: ratio(scattering.Z, absorption.Z),
+	}
+}
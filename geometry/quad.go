@@ -0,0 +1,72 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var _ Hittable = Quad{}
+
+// Quad is a parallelogram with corner Q and edges U and V, for example a
+// leaf or a fence panel. Its surface coordinates run from 0 to 1 along U and
+// V, and its front faces towards U x V.
+type Quad struct {
+	Q    vecmath.Point3
+	U, V vecmath.Vec3
+	M    Material
+}
+
+func (q Quad) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	r.Stats.AddPrimitiveTest()
+
+	var (
+		n     = q.U.Cross(q.V)
+		denom = n.Dot(r.Dir)
+	)
+	// parallel to the plane
+	if math.Abs(denom) < 1e-12 {
+		return false
+	}
+	T := n.Dot(q.Q.Sub(r.Orig)) / denom
+	if T < tmin || tmax < T {
+		return false
+	}
+
+	// planar coordinates of the hit point along U and V
+	var (
+		P = r.At(T)
+		p = P.Sub(q.Q)
+		w = n.DivS(n.LenSq())
+		a = w.Dot(p.Cross(q.V))
+		b = w.Dot(q.U.Cross(p))
+	)
+	if a < 0 || a > 1 || b < 0 || b > 1 {
+		return false
+	}
+
+	temp := NewHitRecord(P, n.Unit(), T, q.M, r)
+	temp.U, temp.V, temp.Tangent, temp.Bitangent = a, b, q.U, q.V
+	if !opaque(temp) {
+		return false
+	}
+	*hr = temp
+	return true
+}
+
+func (q Quad) BoundingBox() AABB {
+	box := AABB{q.Q, q.Q}
+	for _, p := range [3]vecmath.Point3{q.Q.Add(q.U), q.Q.Add(q.V), q.Q.Add(q.U).Add(q.V)} {
+		box = SurroundingBox(box, AABB{p, p})
+	}
+
+	// give flat, axis-aligned quads some thickness
+	const pad = 1e-4
+	for _, axis := range [3]*float64{&box.Min.X, &box.Min.Y, &box.Min.Z} {
+		*axis -= pad
+	}
+	for _, axis := range [3]*float64{&box.Max.X, &box.Max.Y, &box.Max.Z} {
+		*axis += pad
+	}
+	return box
+}
@@ -0,0 +1,67 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// uMask is a material that is only there for u in [min, max).
+type uMask struct {
+	Material
+	min, max float64
+}
+
+func (m uMask) Opaque(hr HitRecord) bool {
+	return m.min <= hr.U && hr.U < m.max
+}
+
+func TestQuadHit(t *testing.T) {
+	// a 2x2 square in the z = -1 plane, facing +Z
+	q := Quad{Q: vecmath.Point3{X: -1, Y: -1, Z: -1}, U: vecmath.Vec3{X: 2, Y: 0, Z: 0}, V: vecmath.Vec3{X: 0, Y: 2, Z: 0}}
+
+	var hr HitRecord
+	if !q.Hit(Ray{Dir: vecmath.Vec3{X: 0.5, Y: -0.5, Z: -1}}, 1e-3, math.MaxFloat64, &hr) {
+		t.Fatalf("missed the quad")
+	}
+	if !almostEqual(hr.T, 1) || !almostEqual(hr.U, 0.75) || !almostEqual(hr.V, 0.25) || !hr.F {
+		t.Errorf("hit at t = %v, uv = %v, %v, front = %v", hr.T, hr.U, hr.V, hr.F)
+	}
+	if !vecAlmostEqual(hr.N, vecmath.Vec3{X: 0, Y: 0, Z: 1}) || hr.Tangent != q.U || hr.Bitangent != q.V {
+		t.Errorf("normal %v, tangents %v, %v", hr.N, hr.Tangent, hr.Bitangent)
+	}
+
+	for _, dir := range []vecmath.Vec3{{X: 1.5, Y: 0, Z: -1}, {X: 1, Y: 0, Z: 0}, {X: 0, Y: 0, Z: 1}} {
+		if q.Hit(Ray{Dir: dir}, 1e-3, math.MaxFloat64, &hr) {
+			t.Errorf("ray along %v hit the quad", dir)
+		}
+	}
+
+	box := q.BoundingBox()
+	if box.Max.Z <= box.Min.Z || !box.Hit(Ray{Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}, 1e-3, math.MaxFloat64) {
+		t.Errorf("flat bounding box %v", box)
+	}
+}
+
+func TestMaskedHit(t *testing.T) {
+	// rays pass through the masked right half of a quad
+	q := Quad{Q: vecmath.Point3{X: -1, Y: -1, Z: -1}, U: vecmath.Vec3{X: 2, Y: 0, Z: 0}, V: vecmath.Vec3{X: 0, Y: 2, Z: 0}, M: uMask{max: 0.5}}
+	var hr HitRecord
+	if !q.Hit(Ray{Dir: vecmath.Vec3{X: -0.5, Y: 0, Z: -1}}, 1e-3, math.MaxFloat64, &hr) {
+		t.Errorf("missed the opaque half")
+	}
+	if q.Hit(Ray{Dir: vecmath.Vec3{X: 0.5, Y: 0, Z: -1}}, 1e-3, math.MaxFloat64, &hr) {
+		t.Errorf("hit the masked half")
+	}
+
+	// through a sphere's masked near side to its far side, u = 0.25 facing
+	// +Z and u = 0.75 facing -Z
+	s := Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -3}, R: 1, M: uMask{min: 0.5, max: 1}}
+	if !s.Hit(Ray{Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}, 1e-3, math.MaxFloat64, &hr) {
+		t.Fatalf("missed the sphere's far side")
+	}
+	if !almostEqual(hr.T, 4) || hr.F {
+		t.Errorf("hit at t = %v, front = %v, want the back face at t = 4", hr.T, hr.F)
+	}
+}
@@ -0,0 +1,120 @@
+package geometry
+
+import (
+	"fmt"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+type Ray struct {
+	Orig, Dir vecmath.Vec3 // A, b
+
+	// counts the work done tracing this ray's path, if non-nil
+	Stats *RayStats
+
+	// draws the random numbers this ray's path is sampled with, or the global
+	// source if nil
+	Rand *vecmath.Rand
+
+	// Time in frames at which the ray's path sees the scene, see Moving
+	Time float64
+}
+
+func (r Ray) At(t float64) vecmath.Vec3 {
+	return r.Orig.Add(r.Dir.MulS(t)) // (A + t*b)
+}
+
+// Termination is the reason a path stopped being traced.
+type Termination int
+
+const (
+	// TermEscaped paths left the scene and picked up the background.
+	TermEscaped Termination = iota
+	// TermAbsorbed paths hit a surface that did not scatter them.
+	TermAbsorbed
+	// TermRoulette paths were ended by Russian roulette.
+	TermRoulette
+	// TermMaxDepth paths reached the camera's maximum depth.
+	TermMaxDepth
+
+	NumTerminations
+)
+
+var terminationNames = [NumTerminations]string{
+	TermEscaped:  "escaped",
+	TermAbsorbed: "absorbed",
+	TermRoulette: "roulette",
+	TermMaxDepth: "maxDepth",
+}
+
+func (t Termination) String() string {
+	if t >= 0 && t < NumTerminations {
+		return terminationNames[t]
+	}
+	return fmt.Sprintf("Termination(%d)", int(t))
+}
+
+// RayStats counts the work done tracing rays. Each pixel gathers its own
+// counts while rendering, see render.CollectStats, and they are merged afterwards,
+// so no synchronization is needed in the hot path. Methods are no-ops on a
+// nil *RayStats.
+type RayStats struct {
+	PrimaryRays int64
+
+	// Rays traced into the scene, primary and secondary
+	Rays int64
+
+	// BVH nodes entered and primitives tested for intersection
+	NodeVisits, PrimitiveTests int64
+
+	// Paths by the reason they ended
+	Terminations [NumTerminations]int64
+}
+
+func (s *RayStats) Merge(o RayStats) {
+	s.PrimaryRays += o.PrimaryRays
+	s.Rays += o.Rays
+	s.NodeVisits += o.NodeVisits
+	s.PrimitiveTests += o.PrimitiveTests
+	for i, n := range o.Terminations {
+		s.Terminations[i] += n
+	}
+}
+
+func (s *RayStats) AddPrimaryRay() {
+	if s != nil {
+		s.PrimaryRays++
+	}
+}
+
+func (s *RayStats) AddRay() {
+	if s != nil {
+		s.Rays++
+	}
+}
+
+func (s *RayStats) AddNodeVisit() {
+	if s != nil {
+		s.NodeVisits++
+	}
+}
+
+func (s *RayStats) AddPrimitiveTest() {
+	if s != nil {
+		s.PrimitiveTests++
+	}
+}
+
+func (s *RayStats) AddTermination(t Termination) {
+	if s != nil {
+		s.Terminations[t]++
+	}
+}
+
+// AvgPathLength returns the mean number of rays traced per path.
+func (s RayStats) AvgPathLength() float64 {
+	if s.PrimaryRays == 0 {
+		return 0
+	}
+	return float64(s.Rays) / float64(s.PrimaryRays)
+}
@@ -0,0 +1,144 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ Hittable = (*Instance)(nil)
+	_ Hittable = (*Moving)(nil)
+)
+
+// Transform places an object in the scene: it is scaled, then rotated about
+// the X, Y and Z axes in turn, then translated.
+type Transform struct {
+	Translate vecmath.Vec3
+
+	// Rotation in degrees about each axis
+	Rotate vecmath.Vec3
+
+	// Scale along each axis; every component must be non-zero
+	Scale vecmath.Vec3
+}
+
+// Identity is the Transform that leaves objects where they are.
+var Identity = Transform{Scale: vecmath.Vec3{X: 1, Y: 1, Z: 1}}
+
+// linear returns the rotation and scale part of xf.
+func (xf Transform) linear() vecmath.Mat3 {
+	var (
+		rad    = xf.Rotate.MulS(math.Pi / 180)
+		sx, cx = math.Sincos(rad.X)
+		sy, cy = math.Sincos(rad.Y)
+		sz, cz = math.Sincos(rad.Z)
+		rx     = vecmath.Mat3{{1, 0, 0}, {0, cx, -sx}, {0, sx, cx}}
+		ry     = vecmath.Mat3{{cy, 0, sy}, {0, 1, 0}, {-sy, 0, cy}}
+		rz     = vecmath.Mat3{{cz, -sz, 0}, {sz, cz, 0}, {0, 0, 1}}
+		s      = vecmath.Mat3{{xf.Scale.X, 0, 0}, {0, xf.Scale.Y, 0}, {0, 0, xf.Scale.Z}}
+	)
+	return rz.Mul(ry).Mul(rx).Mul(s)
+}
+
+// Instance is a Hittable placed in the scene by a Transform. Rays are moved
+// into the object's space rather than the object into world space, so the
+// wrapped Hittable, for example a whole BVH, can be shared between instances.
+type Instance struct {
+	Hittable
+
+	xf  Transform
+	m   vecmath.Mat3 // object to world
+	inv vecmath.Mat3 // world to object
+	box AABB
+}
+
+func NewInstance(obj Hittable, xf Transform) *Instance {
+	var (
+		inst = place(obj, xf)
+		b    = obj.BoundingBox()
+		m    = inst.m
+	)
+
+	// the world space box encloses the object's transformed corners
+	inst.box = AABB{Min: vecmath.Vec3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}, Max: vecmath.Vec3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}}
+	for _, x := range [2]float64{b.Min.X, b.Max.X} {
+		for _, y := range [2]float64{b.Min.Y, b.Max.Y} {
+			for _, z := range [2]float64{b.Min.Z, b.Max.Z} {
+				p := m.MulV(vecmath.Vec3{X: x, Y: y, Z: z}).Add(xf.Translate)
+				inst.box = SurroundingBox(inst.box, AABB{p, p})
+			}
+		}
+	}
+	return inst
+}
+
+// place returns an Instance of obj without a bounding box, for hit testing
+// only.
+func place(obj Hittable, xf Transform) *Instance {
+	m := xf.linear()
+	return &Instance{Hittable: obj, xf: xf, m: m, inv: m.Inverse()}
+}
+
+func (inst *Instance) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	// the direction is not normalized, so t is the same in both spaces
+	local := Ray{
+		Orig:  inst.inv.MulV(r.Orig.Sub(inst.xf.Translate)),
+		Dir:   inst.inv.MulV(r.Dir),
+		Stats: r.Stats,
+		Rand:  r.Rand,
+		Time:  r.Time,
+	}
+	if !inst.Hittable.Hit(local, tmin, tmax, hr) {
+		return false
+	}
+
+	// normals transform by the inverse transpose; this preserves which side
+	// of the surface the ray is on
+	hr.P = r.At(hr.T)
+	hr.N = inst.inv.Transpose().MulV(hr.N).Unit()
+	hr.Tangent = inst.m.MulV(hr.Tangent)
+	hr.Bitangent = inst.m.MulV(hr.Bitangent)
+	return true
+}
+
+func (inst *Instance) BoundingBox() AABB {
+	return inst.box
+}
+
+// movingSteps is the number of intervals the shutter interval of a Moving is
+// split into to bound it, fine enough for the motion within a frame.
+const movingSteps = 16
+
+// Moving is a Hittable whose Transform changes with time, which blurs it
+// while a camera's shutter is open: each ray sees it placed where it is at
+// the ray's Time.
+type Moving struct {
+	Hittable
+
+	at  func(time float64) Transform
+	box AABB
+}
+
+// NewMoving places obj by at(time) for rays over the shutter interval
+// [open, close]. If the interval is empty, it returns the Instance at open.
+func NewMoving(obj Hittable, at func(time float64) Transform, open, close float64) Hittable {
+	if close <= open {
+		return NewInstance(obj, at(open))
+	}
+
+	mv := &Moving{Hittable: obj, at: at, box: NewInstance(obj, at(open)).box}
+	for k := 1; k <= movingSteps; k++ {
+		t := open + (close-open)*float64(k)/movingSteps
+		mv.box = SurroundingBox(mv.box, NewInstance(obj, at(t)).box)
+	}
+	return mv
+}
+
+func (mv *Moving) Hit(r Ray, tmin, tmax float64, hr *HitRecord) bool {
+	return place(mv.Hittable, mv.at(r.Time)).Hit(r, tmin, tmax, hr)
+}
+
+func (mv *Moving) BoundingBox() AABB {
+	return mv.box
+}
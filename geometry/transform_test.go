@@ -0,0 +1,79 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestInstanceTranslateScale(t *testing.T) {
+	var (
+		sphere = Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: 0}, R: 1}
+		inst   = NewInstance(sphere, Transform{Translate: vecmath.Vec3{X: 0, Y: 0, Z: -5}, Scale: vecmath.Vec3{X: 2, Y: 2, Z: 2}})
+		ray    = Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}
+		hr     HitRecord
+	)
+	if !inst.Hit(ray, 0.001, math.MaxFloat64, &hr) {
+		t.Fatal("expected ray to hit instance")
+	}
+	if !almostEqual(hr.T, 3) || !vecAlmostEqual(hr.P, vecmath.Point3{X: 0, Y: 0, Z: -3}) {
+		t.Errorf("hit at t=%v p=%v, want t=3 p=(0,0,-3)", hr.T, hr.P)
+	}
+	if !vecAlmostEqual(hr.N, vecmath.Vec3{X: 0, Y: 0, Z: 1}) || !hr.F {
+		t.Errorf("normal %v front %v, want (0,0,1) front", hr.N, hr.F)
+	}
+
+	box := inst.BoundingBox()
+	if !vecAlmostEqual(box.Min, vecmath.Vec3{X: -2, Y: -2, Z: -7}) || !vecAlmostEqual(box.Max, vecmath.Vec3{X: 2, Y: 2, Z: -3}) {
+		t.Errorf("bounding box %+v", box)
+	}
+}
+
+func TestInstanceRotate(t *testing.T) {
+	var (
+		// an off-axis sphere spun a quarter turn about Y moves from +X to -Z
+		sphere = Sphere{Center: vecmath.Point3{X: 3, Y: 0, Z: 0}, R: 1}
+		inst   = NewInstance(sphere, Transform{Rotate: vecmath.Vec3{X: 0, Y: 90, Z: 0}, Scale: vecmath.Vec3{X: 1, Y: 1, Z: 1}})
+		hr     HitRecord
+	)
+	if inst.Hit(Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 1, Y: 0, Z: 0}}, 0.001, math.MaxFloat64, &hr) {
+		t.Errorf("rotated sphere still hit along +X")
+	}
+	if !inst.Hit(Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}, 0.001, math.MaxFloat64, &hr) {
+		t.Fatal("expected rotated sphere along -Z")
+	}
+	if !almostEqual(hr.T, 2) || !vecAlmostEqual(hr.N, vecmath.Vec3{X: 0, Y: 0, Z: 1}) {
+		t.Errorf("hit at t=%v n=%v, want t=2 n=(0,0,1)", hr.T, hr.N)
+	}
+}
+
+func TestMoving(t *testing.T) {
+	var (
+		// a sphere moving from the origin to +X over frames 1 to 2
+		sphere = Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: 0}, R: 1}
+		at     = func(time float64) Transform {
+			return Transform{Translate: vecmath.Vec3{X: 4 * (time - 1), Y: 0, Z: 0}, Scale: vecmath.Vec3{X: 1, Y: 1, Z: 1}}
+		}
+		mv  = NewMoving(sphere, at, 1, 2)
+		ray = Ray{Orig: vecmath.Point3{X: 4, Y: 0, Z: 5}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -1}}
+		hr  HitRecord
+	)
+	if mv.Hit(ray, 0.001, math.MaxFloat64, &hr) {
+		t.Errorf("sphere hit at x=4 at frame 1")
+	}
+	ray.Time = 2
+	if !mv.Hit(ray, 0.001, math.MaxFloat64, &hr) || !vecAlmostEqual(hr.P, vecmath.Point3{X: 4, Y: 0, Z: 1}) {
+		t.Errorf("sphere not hit at x=4 at frame 2, got %v", hr.P)
+	}
+
+	box := mv.BoundingBox()
+	if !vecAlmostEqual(box.Min, vecmath.Vec3{X: -1, Y: -1, Z: -1}) || !vecAlmostEqual(box.Max, vecmath.Vec3{X: 5, Y: 1, Z: 1}) {
+		t.Errorf("bounding box %+v, want the sphere's from frame 1 to 2", box)
+	}
+
+	// with the shutter closed, it does not move
+	if _, ok := NewMoving(sphere, at, 2, 2).(*Instance); !ok {
+		t.Errorf("NewMoving over an empty interval is not an Instance")
+	}
+}
@@ -0,0 +1,180 @@
+package material
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/spectrum"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Coated)(nil)
+	_ geometry.Albedoer = (*Coated)(nil)
+	_ geometry.Interior = (*Coated)(nil)
+)
+
+// filmSamples is the number of wavelengths a thin film's reflectance is
+// integrated over in RGB mode.
+const filmSamples = 16
+
+// Coated layers a smooth, clear dielectric coat, such as automotive lacquer
+// or varnish, over a base Material. Light reflects off the coat with its
+// Fresnel reflectance F or reaches the base through it, losing 1 - F on the
+// way in and again on the way out; refraction and interreflection between
+// the layers are ignored. Seen from inside a transmitting base, only the
+// base applies.
+type Coated struct {
+	base geometry.Material
+	ir   float64
+
+	// thin film on the coat, thickness in nm, or none if zero
+	film, filmIR float64
+}
+
+type CoatedOpt func(*Coated)
+
+// CoatIOR sets the coat's index of refraction, 1.5 by default.
+func CoatIOR(ir float64) CoatedOpt {
+	return func(c *Coated) {
+		c.ir = ir
+	}
+}
+
+// ThinFilm puts a film of the given thickness, in nm, and index of
+// refraction on the coat. Light reflected off its two sides interferes,
+// coloring the reflection with view-dependent fringes, like soap bubbles and
+// oil slicks; films of a few hundred nm show the strongest colors. A coat of
+// index 1 over a Dielectric of index 1 leaves the film on its own, as in a
+// soap bubble.
+func ThinFilm(thickness, ir float64) CoatedOpt {
+	return func(c *Coated) {
+		c.film = thickness
+		c.filmIR = ir
+	}
+}
+
+func NewCoated(base geometry.Material, opts ...CoatedOpt) Coated {
+	c := Coated{base: base, ir: 1.5}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// fresnel returns the coat's reflectance at cos from the surface normal, at
+// wavelength lambda in every channel in spectral mode, or integrated over
+// the spectrum otherwise.
+func (c Coated) fresnel(cos, lambda float64) vecmath.Color {
+	if c.film <= 0 {
+		f := fresnelDielectric(cos, c.ir)
+		return vecmath.Color{X: f, Y: f, Z: f}
+	}
+	if lambda != 0 {
+		f := filmReflectance(cos, lambda, c.film, c.filmIR, c.ir)
+		return vecmath.Color{X: f, Y: f, Z: f}
+	}
+	f := spectrum.IntegrateRGB(func(lambda float64) float64 {
+		return filmReflectance(cos, lambda, c.film, c.filmIR, c.ir)
+	}, filmSamples)
+	// saturated interference colors can fall outside sRGB
+	return vecmath.Color{X: math.Max(0, math.Min(1, f.X)), Y: math.Max(0, math.Min(1, f.Y)), Z: math.Max(0, math.Min(1, f.Z))}
+}
+
+// filmReflectance returns the unpolarized reflectance at wavelength lambda
+// of a film of the given thickness in nm and index of refraction between
+// air and a substrate of index sub, at cos from the normal, summing the
+// multiple reflections inside the film (the Airy formula).
+func filmReflectance(cos, lambda, thickness, ir, sub float64) float64 {
+	var (
+		cos1  = math.Max(0, math.Min(1, cos))
+		sin2  = 1 - cos1*cos1
+		cos2  = math.Sqrt(math.Max(0, 1-sin2/(ir*ir)))
+		cos3  = math.Sqrt(math.Max(0, 1-sin2/(sub*sub)))
+		phase = cmplx.Exp(complex(0, 4*math.Pi*ir*thickness*cos2/lambda))
+	)
+	airy := func(r12, r23 float64) float64 {
+		r := (complex(r12, 0) + complex(r23, 0)*phase) / (1 + complex(r12*r23, 0)*phase)
+		return norm(r)
+	}
+	var (
+		r12s = (cos1 - ir*cos2) / (cos1 + ir*cos2)
+		r12p = (ir*cos1 - cos2) / (ir*cos1 + cos2)
+		r23s = (ir*cos2 - sub*cos3) / (ir*cos2 + sub*cos3)
+		r23p = (sub*cos2 - ir*cos3) / (sub*cos2 + ir*cos3)
+	)
+	return (airy(r12s, r23s) + airy(r12p, r23p)) / 2
+}
+
+func (c Coated) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	cosO := wo.Dot(hr.N)
+	if !hr.F || cosO <= 0 {
+		return c.base.Sample(wo, hr, bs)
+	}
+
+	var (
+		f = c.fresnel(cosO, hr.Lambda)
+		p = coatProb(f)
+	)
+	if hr.Rand.Float64() < p {
+		*bs = geometry.BSDFSample{
+			Wi:       reflect(wo.Neg(), hr.N),
+			Weight:   f.MulS(1 / p),
+			Specular: true,
+		}
+		return true
+	}
+
+	if !c.base.Sample(wo, hr, bs) {
+		return false
+	}
+	bs.Weight = bs.Weight.Mul(c.through(f, bs.Wi, hr)).MulS(1 / (1 - p))
+	bs.PDF *= 1 - p
+	return true
+}
+
+// coatProb returns the probability of sampling the coat's reflection, which
+// reflects f: as often as it reflects, but leaving room for either layer.
+func coatProb(f vecmath.Color) float64 {
+	return math.Max(0.05, math.Min(0.95, f.Luminance()))
+}
+
+// through returns the fraction of light reaching the base through the coat
+// along wo, with reflectance f, and leaving it again along wi, if wi is
+// above the surface.
+func (c Coated) through(f vecmath.Color, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	white := vecmath.Color{X: 1, Y: 1, Z: 1}
+	t := white.Sub(f)
+	if cosI := wi.Dot(hr.N); cosI > 0 {
+		t = t.Mul(white.Sub(c.fresnel(cosI, hr.Lambda)))
+	}
+	return t
+}
+
+func (c Coated) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	cosO := wo.Dot(hr.N)
+	if !hr.F || cosO <= 0 {
+		return c.base.Eval(wo, wi, hr)
+	}
+	return c.base.Eval(wo, wi, hr).Mul(c.through(c.fresnel(cosO, hr.Lambda), wi, hr))
+}
+
+func (c Coated) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	cosO := wo.Dot(hr.N)
+	if !hr.F || cosO <= 0 {
+		return c.base.PDF(wo, wi, hr)
+	}
+	return c.base.PDF(wo, wi, hr) * (1 - coatProb(c.fresnel(cosO, hr.Lambda)))
+}
+
+func (c Coated) Albedo() vecmath.Color {
+	if a, ok := c.base.(geometry.Albedoer); ok {
+		return a.Albedo()
+	}
+	return vecmath.Color{}
+}
+
+func (c Coated) Interior() (geometry.Medium, bool) {
+	return geometry.MediumOf(c.base)
+}
@@ -0,0 +1,99 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestFilmReflectance(t *testing.T) {
+	// a vanishing film leaves the substrate's own reflectance
+	for _, cos := range []float64{1, 0.7, 0.2} {
+		if got, want := filmReflectance(cos, 550, 1e-9, 1.33, 1.5), fresnelDielectric(cos, 1.5); math.Abs(got-want) > 1e-9 {
+			t.Errorf("bare reflectance at cos %v = %v, want %v", cos, got, want)
+		}
+	}
+
+	// a quarter wave anti-reflection coating cancels reflection at its design
+	// wavelength, but not at others
+	var (
+		sub   = 2.25
+		ir    = math.Sqrt(sub)
+		thick = 550 / (4 * ir)
+	)
+	if r := filmReflectance(1, 550, thick, ir, sub); r > 1e-9 {
+		t.Errorf("anti-reflection coating reflects %v at 550nm", r)
+	}
+	if r := filmReflectance(1, 400, thick, ir, sub); r < 0.01 {
+		t.Errorf("anti-reflection coating reflects %v at 400nm", r)
+	}
+}
+
+func TestCoatedSample(t *testing.T) {
+	var (
+		base = NewDiffusion(vecmath.Color{X: 1, Y: 1, Z: 1}, WithDiffusionType(Lambertian))
+		c    = NewCoated(base)
+		hr   = geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+		wo   = vecmath.Vec3{X: 0.6, Y: 0, Z: 0.8}
+		bs   geometry.BSDFSample
+		sum  vecmath.Color
+		coat int
+	)
+	const n = 20000
+	for range n {
+		if !c.Sample(wo, hr, &bs) {
+			t.Fatalf("Sample failed")
+		}
+		sum = sum.Add(bs.Weight)
+		if bs.Specular {
+			coat++
+			if !vecAlmostEqual(bs.Wi, vecmath.Vec3{X: -0.6, Y: 0, Z: 0.8}) {
+				t.Fatalf("coat reflected along %v", bs.Wi)
+			}
+			continue
+		}
+		// the base lobe's weight agrees with Eval and PDF
+		want := c.Eval(wo, bs.Wi, hr).MulS(bs.Wi.Dot(hr.N) / c.PDF(wo, bs.Wi, hr))
+		if !vecAlmostEqual(bs.Weight, want) {
+			t.Fatalf("base weight %v, want %v", bs.Weight, want)
+		}
+	}
+
+	// a white base under a clear coat loses only what the coat keeps from
+	// leaving, so nearly everything comes back, and no more
+	if mean := sum.DivS(n); mean.X > 1.02 || mean.X < 0.85 || !almostEqual(mean.X, mean.Z) {
+		t.Errorf("mean weight %v", mean)
+	}
+	if coat == 0 {
+		t.Errorf("never sampled the coat")
+	}
+
+	// a coated medium keeps its interior
+	if _, ok := geometry.MediumOf(c); ok {
+		t.Errorf("coated diffuse material has a Medium")
+	}
+	if _, ok := geometry.MediumOf(NewCoated(NewSubsurface(vecmath.Color{X: 1, Y: 1, Z: 1}, vecmath.Color{}))); !ok {
+		t.Errorf("coated Subsurface has no Medium")
+	}
+}
+
+func TestThinFilmColors(t *testing.T) {
+	// interference tints the reflection differently at different angles
+	var (
+		c      = NewCoated(NewDiffusion(vecmath.Color{}), CoatIOR(1.33), ThinFilm(400, 1.45))
+		normal = c.fresnel(1, 0)
+		grazed = c.fresnel(0.5, 0)
+	)
+	if almostEqual(normal.X, normal.Y) && almostEqual(normal.Y, normal.Z) {
+		t.Errorf("thin film reflection %v is gray", normal)
+	}
+	if vecAlmostEqual(normal, grazed) {
+		t.Errorf("thin film reflection %v does not change with angle", normal)
+	}
+	// spectral mode evaluates the one wavelength
+	if f := c.fresnel(1, 550); f.X != filmReflectance(1, 550, 400, 1.45, 1.33) || f.X != f.Z {
+		t.Errorf("spectral thin film reflection %v", f)
+	}
+}
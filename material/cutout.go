@@ -0,0 +1,77 @@
+package material
+
+import (
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/texture"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Cutout)(nil)
+	_ geometry.Masker   = (*Cutout)(nil)
+	_ geometry.Albedoer = (*Cutout)(nil)
+	_ geometry.Interior = (*Cutout)(nil)
+)
+
+// DefaultAlphaThreshold is the alpha below which a Cutout lets rays through.
+const DefaultAlphaThreshold = 0.5
+
+// Cutout masks a Material with an alpha texture: where alpha is below a
+// threshold the surface is not there at all, so that, for example, a quad
+// renders as the leaf painted on it.
+type Cutout struct {
+	geometry.Material
+	alpha      texture.Scalar
+	threshold  float64
+	stochastic bool
+}
+
+type CutoutOpt func(*Cutout)
+
+// AlphaThreshold sets the alpha below which rays pass through, by default
+// DefaultAlphaThreshold.
+func AlphaThreshold(t float64) CutoutOpt {
+	return func(c *Cutout) {
+		c.threshold = t
+	}
+}
+
+// StochasticAlpha lets rays through with probability 1 - alpha instead of
+// at a threshold, so partial alpha, such as a leaf's soft edge, renders as
+// partial coverage once averaged over samples.
+func StochasticAlpha() CutoutOpt {
+	return func(c *Cutout) {
+		c.stochastic = true
+	}
+}
+
+// NewCutout masks m with alpha, which is read at the hit's surface
+// coordinates. To mask with an image's alpha channel, pass
+// (*texture.Image).AlphaChannel(); the image itself, as a Scalar, is its
+// brightness instead.
+func NewCutout(m geometry.Material, alpha texture.Scalar, opts ...CutoutOpt) Cutout {
+	c := Cutout{Material: m, alpha: alpha, threshold: DefaultAlphaThreshold}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c Cutout) Opaque(hr geometry.HitRecord) bool {
+	a := c.alpha.Value(hr.U, hr.V, hr.P)
+	if c.stochastic {
+		return hr.Rand.Float64() < a
+	}
+	return a >= c.threshold
+}
+
+func (c Cutout) Albedo() vecmath.Color {
+	if a, ok := c.Material.(geometry.Albedoer); ok {
+		return a.Albedo()
+	}
+	return vecmath.Color{}
+}
+
+func (c Cutout) Interior() (geometry.Medium, bool) {
+	return geometry.MediumOf(c.Material)
+}
@@ -0,0 +1,88 @@
+package material
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/texture"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestCutout(t *testing.T) {
+	var (
+		alpha = texture.ScalarFunc(func(u, v float64, p vecmath.Point3) float64 { return u })
+		base  = NewDiffusion(vecmath.Color{X: 0.2, Y: 0.4, Z: 0.6})
+	)
+
+	c := NewCutout(base, alpha)
+	for _, tt := range []struct {
+		u    float64
+		want bool
+	}{{0.2, false}, {0.5, true}, {0.9, true}} {
+		if got := c.Opaque(geometry.HitRecord{U: tt.u}); got != tt.want {
+			t.Errorf("Opaque at alpha %v = %v, want %v", tt.u, got, tt.want)
+		}
+	}
+	if c := NewCutout(base, alpha, AlphaThreshold(0.95)); c.Opaque(geometry.HitRecord{U: 0.9}) {
+		t.Errorf("Opaque below a raised threshold")
+	}
+	if a := c.Albedo(); a != base.Albedo() {
+		t.Errorf("Albedo = %v, want the base material's %v", a, base.Albedo())
+	}
+
+	// only a cutout of a medium has one
+	if _, ok := geometry.MediumOf(c); ok {
+		t.Errorf("cutout of a diffuse material has a Medium")
+	}
+	if _, ok := geometry.MediumOf(NewCutout(NewSubsurface(vecmath.Color{X: 1, Y: 1, Z: 1}, vecmath.Color{}), alpha)); !ok {
+		t.Errorf("cutout of a Subsurface has no Medium")
+	}
+
+	// stochastic masks are opaque as often as alpha says
+	var (
+		s      = NewCutout(base, alpha, StochasticAlpha())
+		opaque int
+	)
+	const n = 10000
+	for range n {
+		if s.Opaque(geometry.HitRecord{U: 0.3}) {
+			opaque++
+		}
+	}
+	if f := float64(opaque) / n; f < 0.27 || f > 0.33 {
+		t.Errorf("stochastic mask opaque %v of the time, want 0.3", f)
+	}
+}
+
+func TestCutoutImageAlpha(t *testing.T) {
+	// bright but clear texels on the left, dark but opaque on the right
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	for x := 0; x < 4; x++ {
+		if x < 2 {
+			img.SetNRGBA(x, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 0})
+		} else {
+			img.SetNRGBA(x, 0, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+		}
+	}
+	var (
+		tex   = texture.NewImage(img)
+		base  = NewDiffusion(vecmath.Color{X: 0.2, Y: 0.4, Z: 0.6})
+		left  = geometry.HitRecord{U: 0.125, V: 0.5}
+		right = geometry.HitRecord{U: 0.875, V: 0.5}
+	)
+
+	c := NewCutout(base, tex.AlphaChannel())
+	if c.Opaque(left) {
+		t.Errorf("Opaque on the clear, white texels")
+	}
+	if !c.Opaque(right) {
+		t.Errorf("not Opaque on the opaque, black texels")
+	}
+
+	// the image's own Value follows brightness, not alpha
+	if b := NewCutout(base, tex); !b.Opaque(left) || b.Opaque(right) {
+		t.Errorf("brightness mask should be opaque on white and clear on black")
+	}
+}
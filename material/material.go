@@ -0,0 +1,327 @@
+// Package material implements the surface BSDFs: the book's diffuse, metal
+// and dielectric materials, microfacet conductors and dielectrics, and a
+// principled material.
+package material
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/spectrum"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Metal)(nil)
+	_ geometry.Material = (*Dielectric)(nil)
+	_ geometry.Material = (*Diffusion)(nil)
+	_ geometry.Material = (*ScatterAdapter)(nil)
+
+	_ Scatterer = (*Metal)(nil)
+	_ Scatterer = (*Dielectric)(nil)
+	_ Scatterer = (*Diffusion)(nil)
+
+	_ geometry.Albedoer = (*Metal)(nil)
+	_ geometry.Albedoer = (*Dielectric)(nil)
+	_ geometry.Albedoer = (*Diffusion)(nil)
+)
+
+// Scatterer is the original ray-in, ray-out material interface. See ch 9.
+type Scatterer interface {
+	Scatter(geometry.Ray, geometry.HitRecord, *vecmath.Color, *geometry.Ray) bool
+}
+
+// ScatterAdapter lets a Scatterer be used as a Material. Since Scatter does not
+// report a density, every bounce is treated as a delta lobe.
+type ScatterAdapter struct {
+	S Scatterer
+}
+
+func (a ScatterAdapter) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		att   vecmath.Color
+		scatt geometry.Ray
+	)
+	// reconstruct an incoming ray that reaches hr.P at t = 1
+	if !a.S.Scatter(geometry.Ray{Orig: hr.P.Add(wo), Dir: wo.Neg(), Rand: hr.Rand}, hr, &att, &scatt) {
+		return false
+	}
+	*bs = geometry.BSDFSample{Wi: scatt.Dir.Unit(), Weight: att, Specular: true}
+	return true
+}
+
+func (ScatterAdapter) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	return vecmath.Color{}
+}
+
+func (ScatterAdapter) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	return 0
+}
+
+// scatter implements Scatterer on top of Material.Sample.
+func scatter(m geometry.Material, r geometry.Ray, hr geometry.HitRecord, att *vecmath.Color, scatt *geometry.Ray) bool {
+	var bs geometry.BSDFSample
+	if !m.Sample(r.Dir.Unit().Neg(), hr, &bs) {
+		return false
+	}
+	*att = bs.Weight
+	*scatt = geometry.Ray{Orig: hr.P, Dir: bs.Wi, Rand: r.Rand, Time: r.Time}
+	return true
+}
+
+type material struct {
+	albedo vecmath.Color
+}
+
+func (m material) Albedo() vecmath.Color {
+	return m.albedo
+}
+
+type Metal struct {
+	m    material
+	fuzz float64
+}
+
+type MetalOpt func(*Metal)
+
+// Fuzz applies randomness to reflection of rays from Metal objects.
+// See 9.6.
+func Fuzz(fuzz float64) MetalOpt {
+	return func(m *Metal) {
+		m.fuzz = fuzz
+	}
+}
+
+func NewMetal(albedo vecmath.Color, opts ...MetalOpt) Metal {
+	m := Metal{m: material{albedo: albedo}}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
+}
+
+// Sample - see 9.4.
+func (m Metal) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	wi := reflect(wo.Neg(), hr.N).Add(vecmath.RandomVec3InUnitSphere(hr.Rand).MulS(m.fuzz)) // fuzziness introduced in 9.6
+	if wi.Dot(hr.N) <= 0 {
+		return false
+	}
+	*bs = geometry.BSDFSample{Wi: wi.Unit(), Weight: m.m.albedo, Specular: true}
+	return true
+}
+
+func (Metal) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	return vecmath.Color{}
+}
+
+func (Metal) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	return 0
+}
+
+func (m Metal) Albedo() vecmath.Color {
+	return m.m.Albedo()
+}
+
+func (m Metal) Scatter(r geometry.Ray, hr geometry.HitRecord, att *vecmath.Color, scatt *geometry.Ray) bool {
+	return scatter(m, r, hr, att, scatt)
+}
+
+type Dielectric struct {
+	m     material
+	ir    float64
+	model spectrum.IORModel
+}
+
+type DielectricOpt func(*Dielectric)
+
+// IndexOfRefraction defines the refractive index (eta prime) in Snell's
+// Law equation. See 10.2.
+func IndexOfRefraction(ir float64) DielectricOpt {
+	return func(d *Dielectric) {
+		d.ir = ir
+	}
+}
+
+// Dispersion makes the refractive index depend on wavelength, overriding
+// IndexOfRefraction. Outside of spectral mode the model is evaluated at the
+// Fraunhofer d line.
+func Dispersion(model spectrum.IORModel) DielectricOpt {
+	return func(d *Dielectric) {
+		d.model = model
+	}
+}
+
+func NewDielectric(albedo vecmath.Color, opts ...DielectricOpt) Dielectric {
+	d := Dielectric{m: material{albedo: albedo}, ir: 1.0}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
+}
+
+// ior returns the index of refraction at lambda, or at the d line if lambda
+// is 0.
+func (d Dielectric) ior(lambda float64) float64 {
+	if d.model == nil {
+		return d.ir
+	}
+	if lambda == 0 {
+		lambda = spectrum.LambdaD
+	}
+	return d.model.IOR(lambda)
+}
+
+func (d Dielectric) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		ir    = d.ior(hr.Lambda)
+		ratio float64
+	)
+	if hr.F {
+		ratio = 1.0 / ir
+	} else {
+		ratio = ir
+	}
+
+	udir := wo.Neg()
+	cosT := math.Min(wo.Dot(hr.N), 1)
+	sinT := math.Sqrt(1 - cosT*cosT)
+
+	var dir vecmath.Vec3
+	if ratio*sinT > 1 || d.reflectance(cosT, ratio) > hr.Rand.Float64() {
+		// cannot refract
+		dir = reflect(udir, hr.N)
+	} else {
+		// can refract
+		dir = refract(udir, hr.N, ratio)
+	}
+
+	*bs = geometry.BSDFSample{Wi: dir.Unit(), Weight: d.m.albedo, Specular: true}
+	return true
+}
+
+func (Dielectric) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	return vecmath.Color{}
+}
+
+func (Dielectric) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	return 0
+}
+
+func (d Dielectric) Albedo() vecmath.Color {
+	return d.m.Albedo()
+}
+
+func (d Dielectric) Scatter(r geometry.Ray, hr geometry.HitRecord, att *vecmath.Color, scatt *geometry.Ray) bool {
+	return scatter(d, r, hr, att, scatt)
+}
+
+// reflectance implements Schlick's approximation for reflectance. See 10.4.
+func (d Dielectric) reflectance(cos, ratio float64) (refl float64) {
+	r0 := (1 - ratio) / (1 + ratio)
+	r0 = r0 * r0
+	x := 1 - cos
+	x2 := x * x
+	refl = r0 + (1-r0)*x2*x2*x
+	return
+}
+
+type DiffusionType int
+
+const (
+	Lambertian DiffusionType = iota
+	SimpleDiffusion
+)
+
+type Diffusion struct {
+	m  material
+	dt DiffusionType
+}
+
+type DiffusionOpt func(*Diffusion)
+
+func WithDiffusionType(dt DiffusionType) DiffusionOpt {
+	return func(d *Diffusion) {
+		d.dt = dt
+	}
+}
+
+func NewDiffusion(albedo vecmath.Color, opts ...DiffusionOpt) Diffusion {
+	d := Diffusion{m: material{albedo: albedo}} // default DiffusionType of 0 value (Lambertian)
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
+}
+
+// Sample - see 9.3. Lambertian diffusion is cosine-weighted, so the cosine
+// and density cancel and the weight is simply the albedo.
+func (d Diffusion) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		onb = vecmath.NewONB(hr.N)
+		wi  vecmath.Vec3
+	)
+	switch d.dt {
+	case Lambertian:
+		wi = onb.ToWorld(vecmath.RandomCosineDirection(hr.Rand))
+		*bs = geometry.BSDFSample{Wi: wi, Weight: d.m.albedo, PDF: wi.Dot(hr.N) / math.Pi}
+	case SimpleDiffusion:
+		wi = onb.ToWorld(randomHemisphereDirection(hr.Rand))
+		cos := wi.Dot(hr.N)
+		*bs = geometry.BSDFSample{Wi: wi, Weight: d.m.albedo.MulS(2 * cos), PDF: 1 / (2 * math.Pi)}
+	default:
+		panic("unexpected DiffusionType")
+	}
+	return true
+}
+
+func (d Diffusion) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	if wi.Dot(hr.N) <= 0 {
+		return vecmath.Color{}
+	}
+	return d.m.albedo.MulS(1 / math.Pi)
+}
+
+func (d Diffusion) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	cos := wi.Dot(hr.N)
+	if cos <= 0 {
+		return 0
+	}
+	switch d.dt {
+	case SimpleDiffusion:
+		return 1 / (2 * math.Pi)
+	default:
+		return cos / math.Pi
+	}
+}
+
+func (d Diffusion) Albedo() vecmath.Color {
+	return d.m.Albedo()
+}
+
+func (d Diffusion) Scatter(r geometry.Ray, hr geometry.HitRecord, att *vecmath.Color, scatt *geometry.Ray) bool {
+	return scatter(d, r, hr, att, scatt)
+}
+
+// randomHemisphereDirection returns a unit vector uniformly distributed over
+// the +Z hemisphere.
+func randomHemisphereDirection(rng *vecmath.Rand) vecmath.Vec3 {
+	var (
+		z   = rng.Float64()
+		r   = math.Sqrt(math.Max(0, 1-z*z))
+		phi = 2 * math.Pi * rng.Float64()
+	)
+	return vecmath.Vec3{X: r * math.Cos(phi), Y: r * math.Sin(phi), Z: z}
+}
+
+func reflect(v, n vecmath.Vec3) vecmath.Vec3 {
+	return v.Sub(n.MulS(2 * v.Dot(n)))
+}
+
+func refract(uv, n vecmath.Vec3, eta float64) vecmath.Vec3 {
+	var (
+		cosTheta = math.Min(uv.Neg().Dot(n), 1.0)
+		perp     = n.MulS(cosTheta).Add(uv).MulS(eta)
+		par      = n.MulS(-math.Sqrt(math.Abs(1 - perp.LenSq())))
+	)
+	return perp.Add(par)
+}
@@ -0,0 +1,229 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/spectrum"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func vecAlmostEqual(a, b vecmath.Vec3) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
+
+func TestDiffusionScatterLambertian(t *testing.T) {
+	albedo := vecmath.Color{X: 0.8, Y: 0.3, Z: 0.1}
+	mat := NewDiffusion(albedo, WithDiffusionType(Lambertian))
+
+	hr := geometry.HitRecord{
+		P: vecmath.Point3{X: 0, Y: 0, Z: 0},
+		N: vecmath.Vec3{X: 0, Y: 0, Z: 1},
+		T: 1,
+		F: true,
+	}
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: -1}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: 1}}
+
+	var att vecmath.Color
+	var scatt geometry.Ray
+
+	if !mat.Scatter(r, hr, &att, &scatt) {
+		t.Fatalf("expected diffusion scatter to succeed")
+	}
+
+	if att != albedo {
+		t.Fatalf("attenuation = %#v, want %#v", att, albedo)
+	}
+
+	if scatt.Dir.Len() == 0 {
+		t.Fatalf("scattered direction has zero length")
+	}
+
+	// direction should be roughly in the hemisphere of the normal
+	if scatt.Dir.Dot(hr.N) <= 0 {
+		t.Fatalf("scattered direction not in hemisphere of normal: dot=%v", scatt.Dir.Dot(hr.N))
+	}
+}
+
+func TestMetalScatterReflectsPerfectlyWithZeroFuzz(t *testing.T) {
+	albedo := vecmath.Color{X: 0.8, Y: 0.8, Z: 0.8}
+	metal := NewMetal(albedo, Fuzz(0))
+
+	hr := geometry.HitRecord{
+		P: vecmath.Point3{X: 0, Y: 0, Z: 0},
+		N: vecmath.Vec3{X: 0, Y: 0, Z: 1},
+		T: 1,
+		F: true,
+	}
+
+	// Incoming ray at 45 degrees to the normal
+	inDir := vecmath.Vec3{X: 0, Y: 1, Z: -1}.Unit()
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 1}, Dir: inDir}
+
+	var att vecmath.Color
+	var scatt geometry.Ray
+
+	if !metal.Scatter(r, hr, &att, &scatt) {
+		t.Fatalf("expected metal scatter to succeed")
+	}
+
+	if att != albedo {
+		t.Fatalf("attenuation = %#v, want %#v", att, albedo)
+	}
+
+	wantDir := reflect(inDir, hr.N)
+	if !vecAlmostEqual(scatt.Dir, wantDir) {
+		t.Fatalf("reflected dir = %#v, want %#v", scatt.Dir, wantDir)
+	}
+
+	if scatt.Dir.Dot(hr.N) < 0 {
+		t.Fatalf("reflected direction not above surface: dot=%v", scatt.Dir.Dot(hr.N))
+	}
+}
+
+func TestDielectricScatterTotalInternalReflection(t *testing.T) {
+	// Refractive index > 1, ray going from inside to outside at steep angle
+	albedo := vecmath.Color{X: 1, Y: 1, Z: 1}
+	d := NewDielectric(albedo, IndexOfRefraction(1.5))
+
+	hr := geometry.HitRecord{
+		P: vecmath.Point3{X: 0, Y: 0, Z: 0},
+		N: vecmath.Vec3{X: 0, Y: 0, Z: 1},
+		T: 1,
+		F: false, // ray is inside, heading towards boundary
+	}
+
+	// Choose a direction such that ratio * sin(theta) > 1 for ratio=1.5
+	angle := math.Pi / 3 // 60 degrees
+	inDir := vecmath.Vec3{X: math.Sin(angle), Y: 0, Z: math.Cos(angle)}.Unit()
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: -1}, Dir: inDir}
+
+	var att vecmath.Color
+	var scatt geometry.Ray
+
+	if !d.Scatter(r, hr, &att, &scatt) {
+		t.Fatalf("expected dielectric scatter to succeed")
+	}
+
+	// Total internal reflection should produce a reflected ray
+	reflDir := reflect(inDir, hr.N)
+
+	// Check that scattered direction is close to reflection direction
+	if scatt.Dir.Dot(reflDir) < 0.999 {
+		t.Fatalf("expected total internal reflection, dot(scatt, refl)=%v", scatt.Dir.Dot(reflDir))
+	}
+}
+
+func TestDielectricScatterRefraction(t *testing.T) {
+	albedo := vecmath.Color{X: 1, Y: 1, Z: 1}
+	d := NewDielectric(albedo, IndexOfRefraction(1.5))
+
+	hr := geometry.HitRecord{
+		P: vecmath.Point3{X: 0, Y: 0, Z: 0},
+		N: vecmath.Vec3{X: 0, Y: 0, Z: 1},
+		T: 1,
+		F: true, // ray coming from air into dielectric
+	}
+
+	inDir := vecmath.Vec3{X: 0, Y: 0, Z: -1} // straight on
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 1}, Dir: inDir}
+
+	var att vecmath.Color
+	var scatt geometry.Ray
+
+	if !d.Scatter(r, hr, &att, &scatt) {
+		t.Fatalf("expected dielectric scatter to succeed")
+	}
+
+	// For normal incidence, refraction direction should align with -normal (into the material)
+	if scatt.Dir.Dot(hr.N.Neg()) <= 0.999 {
+		t.Fatalf("expected refracted direction into material, dot=%v", scatt.Dir.Dot(hr.N.Neg()))
+	}
+}
+
+func TestDiffusionSampleMatchesEvalAndPDF(t *testing.T) {
+	albedo := vecmath.Color{X: 0.8, Y: 0.3, Z: 0.1}
+	mat := NewDiffusion(albedo)
+
+	hr := geometry.HitRecord{P: vecmath.Point3{X: 0, Y: 0, Z: 0}, N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, T: 1, F: true}
+	wo := vecmath.Vec3{X: 0, Y: 1, Z: 1}.Unit()
+
+	for i := 0; i < 100; i++ {
+		var bs geometry.BSDFSample
+		if !mat.Sample(wo, hr, &bs) {
+			t.Fatalf("expected diffusion sample to succeed")
+		}
+		if bs.Specular {
+			t.Fatalf("diffusion sample should not be specular")
+		}
+		if !almostEqual(bs.PDF, mat.PDF(wo, bs.Wi, hr)) {
+			t.Fatalf("sample PDF = %v, PDF() = %v", bs.PDF, mat.PDF(wo, bs.Wi, hr))
+		}
+		want := mat.Eval(wo, bs.Wi, hr).MulS(bs.Wi.Dot(hr.N) / bs.PDF)
+		if !vecAlmostEqual(bs.Weight, want) {
+			t.Fatalf("weight = %#v, want f*cos/pdf = %#v", bs.Weight, want)
+		}
+	}
+}
+
+func TestDiffusionBelowSurfaceIsBlack(t *testing.T) {
+	mat := NewDiffusion(vecmath.Color{X: 1, Y: 1, Z: 1})
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+	wo := vecmath.Vec3{X: 0, Y: 0, Z: 1}
+	wi := vecmath.Vec3{X: 0, Y: 0, Z: -1}
+
+	if f := mat.Eval(wo, wi, hr); f != (vecmath.Color{}) {
+		t.Fatalf("Eval below surface = %#v, want black", f)
+	}
+	if pdf := mat.PDF(wo, wi, hr); pdf != 0 {
+		t.Fatalf("PDF below surface = %v, want 0", pdf)
+	}
+}
+
+type constScatterer struct {
+	att vecmath.Color
+	dir vecmath.Vec3
+}
+
+func (c constScatterer) Scatter(r geometry.Ray, hr geometry.HitRecord, att *vecmath.Color, scatt *geometry.Ray) bool {
+	*att = c.att
+	*scatt = geometry.Ray{Orig: hr.P, Dir: c.dir}
+	return true
+}
+
+func TestScatterAdapter(t *testing.T) {
+	s := constScatterer{att: vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5}, dir: vecmath.Vec3{X: 0, Y: 0, Z: 2}}
+	var m geometry.Material = ScatterAdapter{s}
+
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+	var bs geometry.BSDFSample
+	if !m.Sample(vecmath.Vec3{X: 0, Y: 0, Z: 1}, hr, &bs) {
+		t.Fatalf("expected adapter sample to succeed")
+	}
+	if !bs.Specular || bs.PDF != 0 {
+		t.Fatalf("adapter sample should be a delta lobe, got %+v", bs)
+	}
+	if bs.Weight != s.att {
+		t.Fatalf("weight = %#v, want %#v", bs.Weight, s.att)
+	}
+	if !vecAlmostEqual(bs.Wi, vecmath.Vec3{X: 0, Y: 0, Z: 1}) {
+		t.Fatalf("wi = %#v, want unit +Z", bs.Wi)
+	}
+}
+
+func TestDielectricDispersion(t *testing.T) {
+	d := NewDielectric(vecmath.Color{X: 1, Y: 1, Z: 1}, Dispersion(spectrum.Diamond))
+	if d.ior(0) != spectrum.Diamond.IOR(spectrum.LambdaD) {
+		t.Fatalf("RGB mode IOR = %v, want d-line %v", d.ior(0), spectrum.Diamond.IOR(spectrum.LambdaD))
+	}
+	if d.ior(400) <= d.ior(700) {
+		t.Fatalf("expected blue to refract more than red")
+	}
+}
@@ -0,0 +1,486 @@
+package material
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Conductor)(nil)
+	_ geometry.Material = (*RoughDielectric)(nil)
+
+	_ geometry.Albedoer = (*Conductor)(nil)
+	_ geometry.Albedoer = (*RoughDielectric)(nil)
+)
+
+// ggx is the anisotropic Trowbridge-Reitz (GGX) microfacet distribution. All
+// vectors are in the shading frame, with the macro-surface normal along +Z.
+type ggx struct {
+	ax, ay float64
+}
+
+// smoothAlpha is the roughness below which a microfacet lobe is treated as a
+// perfect mirror, since the distribution becomes numerically unstable.
+const smoothAlpha = 1e-3
+
+// newGGX maps perceptual roughness in [0, 1] to GGX alpha (alpha = r^2).
+func newGGX(rx, ry float64) ggx {
+	return ggx{math.Max(rx*rx, 1e-4), math.Max(ry*ry, 1e-4)}
+}
+
+func (g ggx) smooth() bool {
+	return math.Max(g.ax, g.ay) < smoothAlpha
+}
+
+// d is the normal distribution D(wm).
+func (g ggx) d(wm vecmath.Vec3) float64 {
+	if wm.Z <= 0 {
+		return 0
+	}
+	x := wm.X/g.ax*wm.X/g.ax + wm.Y/g.ay*wm.Y/g.ay + wm.Z*wm.Z
+	return 1 / (math.Pi * g.ax * g.ay * x * x)
+}
+
+// lambda is the Smith auxiliary function for direction w.
+func (g ggx) lambda(w vecmath.Vec3) float64 {
+	if w.Z == 0 {
+		return math.Inf(1)
+	}
+	a2t2 := (w.X*w.X*g.ax*g.ax + w.Y*w.Y*g.ay*g.ay) / (w.Z * w.Z)
+	return (math.Sqrt(1+a2t2) - 1) / 2
+}
+
+// g1 is the Smith masking function.
+func (g ggx) g1(w vecmath.Vec3) float64 {
+	return 1 / (1 + g.lambda(w))
+}
+
+// g2 is the height-correlated Smith masking-shadowing function.
+func (g ggx) g2(wo, wi vecmath.Vec3) float64 {
+	return 1 / (1 + g.lambda(wo) + g.lambda(wi))
+}
+
+// dVisible is the distribution of normals visible from w, D_w(wm).
+func (g ggx) dVisible(w, wm vecmath.Vec3) float64 {
+	return g.g1(w) / math.Abs(w.Z) * g.d(wm) * math.Max(0, w.Dot(wm))
+}
+
+// sample draws, from rng, a microfacet normal from the visible normal
+// distribution for w, following Heitz, "Sampling the GGX Distribution of
+// Visible Normals" (JCGT 2018).
+func (g ggx) sample(w vecmath.Vec3, rng *vecmath.Rand) vecmath.Vec3 {
+	// stretch view direction to the hemisphere configuration
+	vh := vecmath.Vec3{X: g.ax * w.X, Y: g.ay * w.Y, Z: w.Z}.Unit()
+	if vh.Z < 0 {
+		vh = vh.Neg()
+	}
+
+	// orthonormal basis around vh
+	t1 := vecmath.Vec3{X: 1, Y: 0, Z: 0}
+	if lensq := vh.X*vh.X + vh.Y*vh.Y; lensq > 0 {
+		t1 = vecmath.Vec3{X: -vh.Y, Y: vh.X, Z: 0}.MulS(1 / math.Sqrt(lensq))
+	}
+	t2 := vh.Cross(t1)
+
+	// sample the projected area
+	var (
+		r   = math.Sqrt(rng.Float64())
+		phi = 2 * math.Pi * rng.Float64()
+		p1  = r * math.Cos(phi)
+		p2  = r * math.Sin(phi)
+		s   = 0.5 * (1 + vh.Z)
+	)
+	p2 = (1-s)*math.Sqrt(1-p1*p1) + s*p2
+
+	// reproject onto the hemisphere and unstretch
+	nh := t1.MulS(p1).Add(t2.MulS(p2)).Add(vh.MulS(math.Sqrt(math.Max(0, 1-p1*p1-p2*p2))))
+	return vecmath.Vec3{X: g.ax * nh.X, Y: g.ay * nh.Y, Z: math.Max(1e-6, nh.Z)}.Unit()
+}
+
+// fresnelDielectric returns the unpolarized Fresnel reflectance at a boundary
+// with relative index of refraction eta (transmitted over incident).
+func fresnelDielectric(cosI, eta float64) float64 {
+	cosI = math.Max(-1, math.Min(1, cosI))
+	if cosI < 0 {
+		eta = 1 / eta
+		cosI = -cosI
+	}
+
+	sin2T := (1 - cosI*cosI) / (eta * eta)
+	if sin2T >= 1 {
+		return 1 // total internal reflection
+	}
+	cosT := math.Sqrt(1 - sin2T)
+
+	rParl := (eta*cosI - cosT) / (eta*cosI + cosT)
+	rPerp := (cosI - eta*cosT) / (cosI + eta*cosT)
+	return (rParl*rParl + rPerp*rPerp) / 2
+}
+
+// fresnelComplex returns the Fresnel reflectance of a conductor with complex
+// index of refraction eta + ik.
+func fresnelComplex(cosI float64, eta complex128) float64 {
+	cosI = math.Max(0, math.Min(1, cosI))
+	var (
+		c     = complex(cosI, 0)
+		sin2T = complex(1-cosI*cosI, 0) / (eta * eta)
+		cosT  = cmplx.Sqrt(1 - sin2T)
+		rParl = (eta*c - cosT) / (eta*c + cosT)
+		rPerp = (c - eta*cosT) / (c + eta*cosT)
+	)
+	return (norm(rParl) + norm(rPerp)) / 2
+}
+
+func norm(z complex128) float64 {
+	return real(z)*real(z) + imag(z)*imag(z)
+}
+
+// refractMicro refracts wo about the microfacet normal wm, both pointing to
+// the incident side, with relative index of refraction eta. It reports false
+// on total internal reflection.
+func refractMicro(wo, wm vecmath.Vec3, eta float64) (vecmath.Vec3, bool) {
+	cosI := wo.Dot(wm)
+	sin2T := math.Max(0, 1-cosI*cosI) / (eta * eta)
+	if sin2T >= 1 {
+		return vecmath.Vec3{}, false
+	}
+	cosT := math.Sqrt(1 - sin2T)
+	return wo.Neg().MulS(1 / eta).Add(wm.MulS(cosI/eta - cosT)), true
+}
+
+// ComplexIOR is the spectral complex index of refraction eta + ik of a
+// conductor, sampled at the R, G and B channels.
+type ComplexIOR struct {
+	Eta, K vecmath.Color
+}
+
+// Measured complex IOR presets for common metals.
+var (
+	Gold     = ComplexIOR{Eta: vecmath.Color{X: 0.143, Y: 0.374, Z: 1.442}, K: vecmath.Color{X: 3.983, Y: 2.386, Z: 1.603}}
+	Copper   = ComplexIOR{Eta: vecmath.Color{X: 0.200, Y: 0.924, Z: 1.102}, K: vecmath.Color{X: 3.912, Y: 2.452, Z: 2.142}}
+	Aluminum = ComplexIOR{Eta: vecmath.Color{X: 1.657, Y: 0.880, Z: 0.521}, K: vecmath.Color{X: 9.224, Y: 6.270, Z: 4.837}}
+)
+
+// fresnel returns the per-channel reflectance at the given cosine.
+func (c ComplexIOR) fresnel(cos float64) vecmath.Color {
+	return vecmath.Color{
+		X: fresnelComplex(cos, complex(c.Eta.X, c.K.X)),
+		Y: fresnelComplex(cos, complex(c.Eta.Y, c.K.Y)),
+		Z: fresnelComplex(cos, complex(c.Eta.Z, c.K.Z)),
+	}
+}
+
+// Conductor is a rough metal using the GGX microfacet model with
+// visible-normal sampling. Unlike Metal, it is energy-conserving and uses the
+// exact conductor Fresnel equations.
+type Conductor struct {
+	ior  ComplexIOR
+	tint vecmath.Color
+	dist ggx
+}
+
+type ConductorOpt func(*Conductor)
+
+// ConductorRoughness sets isotropic perceptual roughness in [0, 1].
+func ConductorRoughness(r float64) ConductorOpt {
+	return func(c *Conductor) {
+		c.dist = newGGX(r, r)
+	}
+}
+
+// ConductorAnisotropicRoughness sets perceptual roughness separately along the
+// tangent and bitangent of the surface, for brushed metals.
+func ConductorAnisotropicRoughness(rx, ry float64) ConductorOpt {
+	return func(c *Conductor) {
+		c.dist = newGGX(rx, ry)
+	}
+}
+
+// ConductorTint multiplies the Fresnel reflectance, for artistic control.
+func ConductorTint(tint vecmath.Color) ConductorOpt {
+	return func(c *Conductor) {
+		c.tint = tint
+	}
+}
+
+func NewConductor(ior ComplexIOR, opts ...ConductorOpt) Conductor {
+	c := Conductor{ior: ior, tint: vecmath.Color{X: 1, Y: 1, Z: 1}, dist: ggx{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c Conductor) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+	)
+	if wol.Z <= 0 {
+		return false
+	}
+
+	if c.dist.smooth() {
+		wi := vecmath.Vec3{X: -wol.X, Y: -wol.Y, Z: wol.Z}
+		*bs = geometry.BSDFSample{Wi: onb.ToWorld(wi), Weight: c.ior.fresnel(wol.Z).Mul(c.tint), Specular: true}
+		return true
+	}
+
+	wm := c.dist.sample(wol, hr.Rand)
+	wil := reflect(wol.Neg(), wm)
+	if wil.Z <= 0 {
+		return false
+	}
+
+	// f * cos / pdf reduces to F * G2 / G1 for visible-normal sampling
+	var (
+		cos    = wol.Dot(wm)
+		weight = c.ior.fresnel(cos).Mul(c.tint).MulS(c.dist.g2(wol, wil) / c.dist.g1(wol))
+		pdf    = c.dist.dVisible(wol, wm) / (4 * cos)
+	)
+	*bs = geometry.BSDFSample{Wi: onb.ToWorld(wil), Weight: weight, PDF: pdf}
+	return true
+}
+
+// Albedo is the reflectance at normal incidence.
+func (c Conductor) Albedo() vecmath.Color {
+	return c.ior.fresnel(1).Mul(c.tint)
+}
+
+func (c Conductor) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	if c.dist.smooth() {
+		return vecmath.Color{}
+	}
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+		wil = onb.ToLocal(wi)
+	)
+	if wol.Z <= 0 || wil.Z <= 0 {
+		return vecmath.Color{}
+	}
+	wm := wol.Add(wil)
+	if wm.NearZero() {
+		return vecmath.Color{}
+	}
+	wm = wm.Unit()
+
+	f := c.dist.d(wm) * c.dist.g2(wol, wil) / (4 * wol.Z * wil.Z)
+	return c.ior.fresnel(wol.Dot(wm)).Mul(c.tint).MulS(f)
+}
+
+func (c Conductor) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	if c.dist.smooth() {
+		return 0
+	}
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+		wil = onb.ToLocal(wi)
+	)
+	if wol.Z <= 0 || wil.Z <= 0 {
+		return 0
+	}
+	wm := wol.Add(wil)
+	if wm.NearZero() {
+		return 0
+	}
+	wm = wm.Unit()
+	return c.dist.dVisible(wol, wm) / (4 * wol.Dot(wm))
+}
+
+// RoughDielectric is frosted glass using the GGX microfacet model for both
+// reflection and transmission (Walter et al., "Microfacet Models for
+// Refraction through Rough Surfaces", EGSR 2007).
+type RoughDielectric struct {
+	m    material
+	ir   float64
+	dist ggx
+}
+
+type RoughDielectricOpt func(*RoughDielectric)
+
+// RoughDielectricIOR sets the index of refraction of the material.
+func RoughDielectricIOR(ir float64) RoughDielectricOpt {
+	return func(d *RoughDielectric) {
+		d.ir = ir
+	}
+}
+
+// RoughDielectricRoughness sets isotropic perceptual roughness in [0, 1].
+func RoughDielectricRoughness(r float64) RoughDielectricOpt {
+	return func(d *RoughDielectric) {
+		d.dist = newGGX(r, r)
+	}
+}
+
+func NewRoughDielectric(albedo vecmath.Color, opts ...RoughDielectricOpt) RoughDielectric {
+	d := RoughDielectric{m: material{albedo: albedo}, ir: 1.5, dist: ggx{}}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	return d
+}
+
+// eta returns the relative index of refraction across the boundary for a ray
+// arriving on the side described by hr.
+func (d RoughDielectric) eta(hr geometry.HitRecord) float64 {
+	if hr.F {
+		return d.ir
+	}
+	return 1 / d.ir
+}
+
+func (d RoughDielectric) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+		eta = d.eta(hr)
+	)
+	if wol.Z <= 0 {
+		return false
+	}
+
+	if d.dist.smooth() {
+		n := vecmath.Vec3{X: 0, Y: 0, Z: 1}
+		wil := vecmath.Vec3{X: -wol.X, Y: -wol.Y, Z: wol.Z}
+		if hr.Rand.Float64() >= fresnelDielectric(wol.Z, eta) {
+			if wt, ok := refractMicro(wol, n, eta); ok {
+				wil = wt
+			}
+		}
+		*bs = geometry.BSDFSample{Wi: onb.ToWorld(wil).Unit(), Weight: d.m.albedo, Specular: true}
+		return true
+	}
+
+	var (
+		wm  = d.dist.sample(wol, hr.Rand)
+		cos = wol.Dot(wm)
+		fr  = fresnelDielectric(cos, eta)
+	)
+
+	if hr.Rand.Float64() < fr {
+		// reflection
+		wil := reflect(wol.Neg(), wm)
+		if wil.Z <= 0 {
+			return false
+		}
+		pdf := d.dist.dVisible(wol, wm) / (4 * cos) * fr
+		*bs = geometry.BSDFSample{
+			Wi:     onb.ToWorld(wil),
+			Weight: d.m.albedo.MulS(d.dist.g2(wol, wil) / d.dist.g1(wol)),
+			PDF:    pdf,
+		}
+		return true
+	}
+
+	// transmission
+	wil, ok := refractMicro(wol, wm, eta)
+	if !ok || wil.Z >= 0 {
+		return false
+	}
+	wil = wil.Unit()
+	*bs = geometry.BSDFSample{
+		Wi:     onb.ToWorld(wil),
+		Weight: d.m.albedo.MulS(d.dist.g2(wol, wil) / d.dist.g1(wol)),
+		PDF:    d.pdfLocal(wol, wil, eta),
+	}
+	return true
+}
+
+func (d RoughDielectric) Albedo() vecmath.Color {
+	return d.m.Albedo()
+}
+
+// halfVector returns the generalized half vector for wo and wi in the shading
+// frame, oriented into the +Z hemisphere, or false for degenerate
+// configurations.
+func (d RoughDielectric) halfVector(wol, wil vecmath.Vec3, eta float64) (vecmath.Vec3, bool) {
+	refl := wil.Z > 0
+	wm := wol.Add(wil)
+	if !refl {
+		wm = wol.Add(wil.MulS(eta))
+	}
+	if wm.NearZero() {
+		return vecmath.Vec3{}, false
+	}
+	wm = wm.Unit()
+	if wm.Z < 0 {
+		wm = wm.Neg()
+	}
+	// discard back-facing microfacets
+	if wm.Dot(wol) <= 0 || (wm.Dot(wil) > 0) != refl {
+		return vecmath.Vec3{}, false
+	}
+	return wm, true
+}
+
+func (d RoughDielectric) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	if d.dist.smooth() {
+		return vecmath.Color{}
+	}
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+		wil = onb.ToLocal(wi)
+		eta = d.eta(hr)
+	)
+	if wol.Z <= 0 || wil.Z == 0 {
+		return vecmath.Color{}
+	}
+	wm, ok := d.halfVector(wol, wil, eta)
+	if !ok {
+		return vecmath.Color{}
+	}
+
+	var (
+		fr = fresnelDielectric(wol.Dot(wm), eta)
+		dg = d.dist.d(wm) * d.dist.g2(wol, wil)
+	)
+	if wil.Z > 0 {
+		return d.m.albedo.MulS(dg * fr / (4 * wol.Z * wil.Z))
+	}
+
+	// Radiance is not scaled by 1/eta^2 here, matching Dielectric; the factors
+	// cancel for paths that enter and leave a closed object.
+	denom := wil.Dot(wm)*eta + wol.Dot(wm)
+	denom *= denom
+	f := dg * (1 - fr) * math.Abs(wil.Dot(wm)*wol.Dot(wm)/(wil.Z*wol.Z*denom)) * eta * eta
+	return d.m.albedo.MulS(f)
+}
+
+func (d RoughDielectric) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	if d.dist.smooth() {
+		return 0
+	}
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+		wil = onb.ToLocal(wi)
+	)
+	if wol.Z <= 0 || wil.Z == 0 {
+		return 0
+	}
+	return d.pdfLocal(wol, wil, d.eta(hr))
+}
+
+func (d RoughDielectric) pdfLocal(wol, wil vecmath.Vec3, eta float64) float64 {
+	wm, ok := d.halfVector(wol, wil, eta)
+	if !ok {
+		return 0
+	}
+	var (
+		cos = wol.Dot(wm)
+		fr  = fresnelDielectric(cos, eta)
+		dv  = d.dist.dVisible(wol, wm)
+	)
+	if wil.Z > 0 {
+		return dv / (4 * cos) * fr
+	}
+	denom := wil.Dot(wm)*eta + cos
+	denom *= denom
+	return dv * math.Abs(wil.Dot(wm)) * eta * eta / denom * (1 - fr)
+}
@@ -0,0 +1,124 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestFresnelDielectricNormalIncidence(t *testing.T) {
+	want := math.Pow((1.5-1)/(1.5+1), 2)
+	if got := fresnelDielectric(1, 1.5); !almostEqual(got, want) {
+		t.Fatalf("fresnelDielectric(1, 1.5) = %v, want %v", got, want)
+	}
+	if got := fresnelDielectric(0.1, 1/1.5); got != 1 {
+		t.Fatalf("expected total internal reflection, got %v", got)
+	}
+}
+
+func TestFresnelComplexMatchesDielectric(t *testing.T) {
+	for _, cos := range []float64{0.2, 0.5, 1} {
+		if got, want := fresnelComplex(cos, complex(1.5, 0)), fresnelDielectric(cos, 1.5); !almostEqual(got, want) {
+			t.Fatalf("fresnelComplex(%v) = %v, want %v", cos, got, want)
+		}
+	}
+}
+
+func TestGGXSampleIsVisible(t *testing.T) {
+	g := newGGX(0.5, 0.2)
+	wo := vecmath.Vec3{X: 0.5, Y: 0.1, Z: 0.8}.Unit()
+	for i := 0; i < 1000; i++ {
+		wm := g.sample(wo, nil)
+		if wm.Z <= 0 || wm.Dot(wo) < -floatEps {
+			t.Fatalf("sampled back-facing microfacet %#v", wm)
+		}
+		if !almostEqual(wm.Len(), 1) {
+			t.Fatalf("microfacet normal not unit length: %v", wm.Len())
+		}
+	}
+}
+
+// checkSampleConsistency verifies Sample's weight and density agree with Eval
+// and PDF for non-delta samples.
+func checkSampleConsistency(t *testing.T, m geometry.Material, wo vecmath.Vec3, hr geometry.HitRecord) {
+	t.Helper()
+	n := 0
+	for i := 0; i < 500; i++ {
+		var bs geometry.BSDFSample
+		if !m.Sample(wo, hr, &bs) || bs.Specular {
+			continue
+		}
+		n++
+		pdf := m.PDF(wo, bs.Wi, hr)
+		if math.Abs(pdf-bs.PDF) > 1e-6*math.Max(1, pdf) {
+			t.Fatalf("sample PDF = %v, PDF() = %v", bs.PDF, pdf)
+		}
+		want := m.Eval(wo, bs.Wi, hr).MulS(math.Abs(bs.Wi.Dot(hr.N)) / bs.PDF)
+		if math.Abs(want.X-bs.Weight.X) > 1e-6 || math.Abs(want.Y-bs.Weight.Y) > 1e-6 || math.Abs(want.Z-bs.Weight.Z) > 1e-6 {
+			t.Fatalf("weight = %#v, want f*cos/pdf = %#v", bs.Weight, want)
+		}
+	}
+	if n == 0 {
+		t.Fatalf("no non-delta samples drawn")
+	}
+}
+
+func TestConductorSampleConsistency(t *testing.T) {
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+	wo := vecmath.Vec3{X: 0.3, Y: 0, Z: 1}.Unit()
+	checkSampleConsistency(t, NewConductor(Gold, ConductorRoughness(0.4)), wo, hr)
+	checkSampleConsistency(t, NewConductor(Aluminum, ConductorAnisotropicRoughness(0.6, 0.1)), wo, hr)
+}
+
+func TestConductorAnisotropyFollowsTangent(t *testing.T) {
+	var (
+		m   = NewConductor(Aluminum, ConductorAnisotropicRoughness(0.1, 0.5))
+		n   = vecmath.Vec3{X: 0, Y: 0, Z: 1}
+		wo  = n
+		wiX = vecmath.Vec3{X: 0.3, Y: 0, Z: 1}.Unit()
+		wiY = vecmath.Vec3{X: 0, Y: 0.3, Z: 1}.Unit()
+
+		alongX = geometry.HitRecord{N: n, F: true, Tangent: vecmath.Vec3{X: 2, Y: 0, Z: 0}}
+		alongY = geometry.HitRecord{N: n, F: true, Tangent: vecmath.Vec3{X: 0, Y: 2, Z: 0}}
+	)
+
+	// the highlight is narrow along the tangent and wide across it
+	if x, y := m.Eval(wo, wiX, alongX).X, m.Eval(wo, wiY, alongX).X; x >= y {
+		t.Fatalf("tangent along X: f(wiX) = %v, want less than f(wiY) = %v", x, y)
+	}
+
+	// rotating the tangent a quarter turn rotates the highlight with it
+	if got, want := m.Eval(wo, wiY, alongY), m.Eval(wo, wiX, alongX); !vecAlmostEqual(got, want) {
+		t.Fatalf("tangent along Y: f(wiY) = %#v, want %#v", got, want)
+	}
+	if got, want := m.Eval(wo, wiX, alongY), m.Eval(wo, wiY, alongX); !vecAlmostEqual(got, want) {
+		t.Fatalf("tangent along Y: f(wiX) = %#v, want %#v", got, want)
+	}
+	checkSampleConsistency(t, m, vecmath.Vec3{X: 0.3, Y: 0.2, Z: 1}.Unit(), alongY)
+}
+
+func TestRoughDielectricSampleConsistency(t *testing.T) {
+	wo := vecmath.Vec3{X: 0.3, Y: 0, Z: 1}.Unit()
+	m := NewRoughDielectric(vecmath.Color{X: 1, Y: 1, Z: 1}, RoughDielectricIOR(1.5), RoughDielectricRoughness(0.3))
+	checkSampleConsistency(t, m, wo, geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true})
+	checkSampleConsistency(t, m, wo, geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: false})
+}
+
+func TestSmoothConductorIsMirror(t *testing.T) {
+	m := NewConductor(Copper)
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+	wo := vecmath.Vec3{X: 0, Y: 1, Z: 1}.Unit()
+
+	var bs geometry.BSDFSample
+	if !m.Sample(wo, hr, &bs) {
+		t.Fatalf("expected conductor sample to succeed")
+	}
+	if !bs.Specular {
+		t.Fatalf("smooth conductor should sample a delta lobe")
+	}
+	if want := reflect(wo.Neg(), hr.N); !vecAlmostEqual(bs.Wi, want) {
+		t.Fatalf("wi = %#v, want %#v", bs.Wi, want)
+	}
+}
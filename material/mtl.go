@@ -0,0 +1,125 @@
+package material
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// ReadMTL reads a Wavefront MTL material library, mapping each material onto
+// PrincipledParams:
+//
+//	Kd          base color
+//	Ks          specular, as the mean of the channels
+//	Ns          roughness, converted from the Phong exponent; Pr overrides it
+//	Pr          roughness
+//	Pm          metallic
+//	Ps          sheen
+//	Pc, Pcr     clearcoat and clearcoat roughness
+//	Ni          index of refraction
+//	d, Tr       transmission, as 1-d or Tr
+//
+// Other statements, including texture maps, are ignored.
+func ReadMTL(r io.Reader) (map[string]PrincipledParams, error) {
+	var (
+		materials = make(map[string]PrincipledParams)
+		sc        = bufio.NewScanner(r)
+		name      string
+		pp        PrincipledParams
+		pr        bool // roughness set by Pr, which takes precedence over Ns
+		line      int
+	)
+	flush := func() {
+		if name != "" {
+			materials[name] = pp
+		}
+	}
+
+	for sc.Scan() {
+		line++
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		key, args := fields[0], fields[1:]
+
+		if key == "newmtl" {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("MTL line %d: newmtl needs one name", line)
+			}
+			flush()
+			name, pp, pr = args[0], DefaultPrincipledParams(vecmath.Color{X: 0.8, Y: 0.8, Z: 0.8}), false
+			continue
+		}
+
+		switch key {
+		case "Kd", "Ks", "Ns", "Pr", "Pm", "Ps", "Pc", "Pcr", "Ni", "d", "Tr":
+		default:
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("MTL line %d: %s before newmtl", line, key)
+		}
+		values := make([]float64, len(args))
+		for k, a := range args {
+			v, err := strconv.ParseFloat(a, 64)
+			if err != nil {
+				return nil, fmt.Errorf("MTL line %d: %s: %w", line, key, err)
+			}
+			values[k] = v
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("MTL line %d: %s needs a value", line, key)
+		}
+
+		switch key {
+		case "Kd":
+			pp.BaseColor = mtlColor(values)
+		case "Ks":
+			c := mtlColor(values)
+			pp.Specular = (c.X + c.Y + c.Z) / 3
+		case "Ns":
+			if !pr {
+				// a Phong exponent matches Beckmann alpha sqrt(2/(Ns+2)), and
+				// alpha is perceptual roughness squared
+				pp.Roughness = math.Pow(2/(math.Max(values[0], 0)+2), 0.25)
+			}
+		case "Pr":
+			pp.Roughness, pr = values[0], true
+		case "Pm":
+			pp.Metallic = values[0]
+		case "Ps":
+			c := mtlColor(values)
+			pp.Sheen = (c.X + c.Y + c.Z) / 3
+		case "Pc":
+			pp.Clearcoat = values[0]
+		case "Pcr":
+			pp.ClearcoatRoughness = values[0]
+		case "Ni":
+			pp.IOR = values[0]
+		case "d":
+			pp.Transmission = 1 - values[0]
+		case "Tr":
+			pp.Transmission = values[0]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading MTL: %w", err)
+	}
+	flush()
+	return materials, nil
+}
+
+// mtlColor reads an MTL color, given as r g b or a single gray value.
+func mtlColor(values []float64) vecmath.Color {
+	if len(values) < 3 {
+		return vecmath.Color{X: values[0], Y: values[0], Z: values[0]}
+	}
+	return vecmath.Color{X: values[0], Y: values[1], Z: values[2]}
+}
@@ -0,0 +1,83 @@
+package material
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestReadMTL(t *testing.T) {
+	const lib = `# two materials
+newmtl paint
+Kd 0.6 0.1 0.1
+Ks 0.5 0.5 0.5
+Ns 98
+Pc 1
+Pcr 0.05
+
+newmtl gold
+Kd 1 0.8 0.3
+Pr 0.2
+Ns 10   # Pr wins
+Pm 1
+map_Kd gold.png
+
+newmtl glass
+Kd 1
+Ni 1.45
+d 0.25
+`
+	materials, err := ReadMTL(strings.NewReader(lib))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paint := DefaultPrincipledParams(vecmath.Color{X: 0.6, Y: 0.1, Z: 0.1})
+	paint.Roughness = math.Pow(0.02, 0.25)
+	paint.Clearcoat, paint.ClearcoatRoughness = 1, 0.05
+
+	gold := DefaultPrincipledParams(vecmath.Color{X: 1, Y: 0.8, Z: 0.3})
+	gold.Roughness, gold.Metallic = 0.2, 1
+
+	glass := DefaultPrincipledParams(vecmath.Color{X: 1, Y: 1, Z: 1})
+	glass.IOR, glass.Transmission = 1.45, 0.75
+
+	for name, want := range map[string]PrincipledParams{"paint": paint, "gold": gold, "glass": glass} {
+		got, ok := materials[name]
+		if !ok {
+			t.Fatalf("material %q missing", name)
+		}
+		if got.BaseColor != want.BaseColor || !almostEqual(got.Roughness, want.Roughness) {
+			t.Fatalf("%s = %#v, want %#v", name, got, want)
+		}
+		got.Roughness = want.Roughness
+		if got != want {
+			t.Fatalf("%s = %#v, want %#v", name, got, want)
+		}
+	}
+	if len(materials) != 3 {
+		t.Fatalf("read %d materials, want 3", len(materials))
+	}
+}
+
+func TestReadMTLErrors(t *testing.T) {
+	for _, lib := range []string{
+		"Kd 1 1 1\n",
+		"newmtl a\nKd red\n",
+		"newmtl a\nNs\n",
+		"newmtl\n",
+	} {
+		if _, err := ReadMTL(strings.NewReader(lib)); err == nil {
+			t.Fatalf("expected an error reading %q", lib)
+		}
+	}
+}
+
+func TestDefaultPrincipledParams(t *testing.T) {
+	c := vecmath.Color{X: 0.2, Y: 0.4, Z: 0.6}
+	if got, want := DefaultPrincipledParams(c).Principled(), NewPrincipled(c); got != want {
+		t.Fatalf("default params give %#v, want %#v", got, want)
+	}
+}
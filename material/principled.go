@@ -0,0 +1,337 @@
+package material
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Principled)(nil)
+	_ geometry.Albedoer = (*Principled)(nil)
+)
+
+// Principled is a layered uber material after Burley, "Physically Based
+// Shading at Disney" (SIGGRAPH 2012). It blends a diffuse + sheen base, a GGX
+// specular lobe, a rough dielectric transmission lobe and a GGX clearcoat,
+// so one material covers plastics, metals and glass.
+type Principled struct {
+	baseColor          vecmath.Color
+	metallic           float64
+	roughness          float64
+	specular           float64
+	sheen, sheenTint   float64
+	clearcoat          float64
+	clearcoatRoughness float64
+	transmission       float64
+	ir                 float64
+
+	// derived in NewPrincipled
+	spec, coat ggx
+	glass      RoughDielectric
+}
+
+type PrincipledOpt func(*Principled)
+
+// Metallic blends between a dielectric (0) and a conductor (1) whose
+// reflectance is the base color.
+func Metallic(metallic float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.metallic = metallic
+	}
+}
+
+// Roughness sets the perceptual roughness of the specular and transmission
+// lobes in [0, 1].
+func Roughness(roughness float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.roughness = roughness
+	}
+}
+
+// Specular scales dielectric reflectance at normal incidence; 0.5 gives the
+// common F0 of 4%.
+func Specular(specular float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.specular = specular
+	}
+}
+
+// Sheen adds a grazing retro-reflective layer for cloth, optionally tinted
+// towards the base color.
+func Sheen(sheen, tint float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.sheen = sheen
+		p.sheenTint = tint
+	}
+}
+
+// Clearcoat adds a second, colorless specular layer with its own roughness.
+func Clearcoat(clearcoat, roughness float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.clearcoat = clearcoat
+		p.clearcoatRoughness = roughness
+	}
+}
+
+// Transmission blends the dielectric base towards glass with index of
+// refraction ir.
+func Transmission(transmission, ir float64) PrincipledOpt {
+	return func(p *Principled) {
+		p.transmission = transmission
+		p.ir = ir
+	}
+}
+
+func NewPrincipled(baseColor vecmath.Color, opts ...PrincipledOpt) Principled {
+	p := Principled{
+		baseColor:          baseColor,
+		roughness:          0.5,
+		specular:           0.5,
+		clearcoatRoughness: 0.1,
+		ir:                 1.5,
+	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	p.spec = newGGX(p.roughness, p.roughness)
+	cr := math.Max(p.clearcoatRoughness, 0.05)
+	p.coat = newGGX(cr, cr)
+	p.glass = NewRoughDielectric(baseColor, RoughDielectricIOR(p.ir), RoughDielectricRoughness(p.roughness))
+	return p
+}
+
+// PrincipledParams are the parameters of a Principled material as plain
+// values, for materials described in scene files and MTL libraries.
+type PrincipledParams struct {
+	BaseColor                     vecmath.Color
+	Metallic, Roughness, Specular float64
+	Sheen, SheenTint              float64
+	Clearcoat, ClearcoatRoughness float64
+	Transmission, IOR             float64
+}
+
+// DefaultPrincipledParams returns the parameters of NewPrincipled(baseColor).
+func DefaultPrincipledParams(baseColor vecmath.Color) PrincipledParams {
+	return PrincipledParams{
+		BaseColor:          baseColor,
+		Roughness:          0.5,
+		Specular:           0.5,
+		ClearcoatRoughness: 0.1,
+		IOR:                1.5,
+	}
+}
+
+// Principled returns the material with parameters pp.
+func (pp PrincipledParams) Principled() Principled {
+	return NewPrincipled(pp.BaseColor,
+		Metallic(pp.Metallic),
+		Roughness(pp.Roughness),
+		Specular(pp.Specular),
+		Sheen(pp.Sheen, pp.SheenTint),
+		Clearcoat(pp.Clearcoat, pp.ClearcoatRoughness),
+		Transmission(pp.Transmission, pp.IOR))
+}
+
+func schlick(f0 vecmath.Color, cos float64) vecmath.Color {
+	x := 1 - math.Max(0, math.Min(1, cos))
+	x5 := x * x * x * x * x
+	return f0.Add(vecmath.Color{X: 1, Y: 1, Z: 1}.Sub(f0).MulS(x5))
+}
+
+// diffuseWeight is the fraction of the base that is neither metal nor glass.
+func (p Principled) diffuseWeight() float64 {
+	return (1 - p.metallic) * (1 - p.transmission)
+}
+
+// specFresnel is the reflectance of the main specular lobe, already scaled by
+// the dielectric and metallic blend weights.
+func (p Principled) specFresnel(cos float64) vecmath.Color {
+	var (
+		f0d = 0.08 * p.specular
+		fd  = schlick(vecmath.Color{X: f0d, Y: f0d, Z: f0d}, cos).MulS(p.diffuseWeight())
+		fm  = schlick(p.baseColor, cos).MulS(p.metallic)
+	)
+	return fd.Add(fm)
+}
+
+func (p Principled) coatFresnel(cos float64) float64 {
+	return 0.25 * p.clearcoat * schlick(vecmath.Color{X: 0.04, Y: 0.04, Z: 0.04}, cos).X
+}
+
+// lobe indexes the probability table returned by lobeProbs.
+const (
+	lobeDiffuse = iota
+	lobeSpecular
+	lobeGlass
+	lobeCoat
+	numLobes
+)
+
+// lobeProbs returns the probability of sampling each lobe, based on its
+// approximate albedo as seen from wo.
+func (p Principled) lobeProbs(cosO float64) (probs [numLobes]float64, ok bool) {
+	probs[lobeDiffuse] = p.diffuseWeight() * (p.baseColor.Luminance() + p.sheen)
+	probs[lobeSpecular] = p.specFresnel(cosO).Luminance()
+	probs[lobeGlass] = (1 - p.metallic) * p.transmission
+	probs[lobeCoat] = p.coatFresnel(cosO)
+
+	var sum float64
+	for _, pr := range probs {
+		sum += pr
+	}
+	if sum <= 0 {
+		return probs, false
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	return probs, true
+}
+
+func (p Principled) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+	)
+	if wol.Z <= 0 {
+		return false
+	}
+	probs, ok := p.lobeProbs(wol.Z)
+	if !ok {
+		return false
+	}
+
+	lobe, u := 0, hr.Rand.Float64()
+	for ; lobe < numLobes-1; lobe++ {
+		if u < probs[lobe] {
+			break
+		}
+		u -= probs[lobe]
+	}
+
+	var wil vecmath.Vec3
+	switch lobe {
+	case lobeDiffuse:
+		wil = vecmath.RandomCosineDirection(hr.Rand)
+	case lobeSpecular:
+		if p.spec.smooth() {
+			wil = vecmath.Vec3{X: -wol.X, Y: -wol.Y, Z: wol.Z}
+			*bs = geometry.BSDFSample{
+				Wi:       onb.ToWorld(wil),
+				Weight:   p.specFresnel(wol.Z).MulS(1 / probs[lobeSpecular]),
+				Specular: true,
+			}
+			return true
+		}
+		wil = reflect(wol.Neg(), p.spec.sample(wol, hr.Rand))
+	case lobeGlass:
+		var gs geometry.BSDFSample
+		if !p.glass.Sample(wo, hr, &gs) {
+			return false
+		}
+		if gs.Specular {
+			gs.Weight = gs.Weight.MulS((1 - p.metallic) * p.transmission / probs[lobeGlass])
+			*bs = gs
+			return true
+		}
+		wil = onb.ToLocal(gs.Wi)
+	case lobeCoat:
+		wil = reflect(wol.Neg(), p.coat.sample(wol, hr.Rand))
+	}
+
+	wi := onb.ToWorld(wil)
+	pdf := p.pdf(wo, wi, wol, wil, hr, probs)
+	if pdf <= 0 {
+		return false
+	}
+	f := p.eval(wo, wi, wol, wil, hr)
+	*bs = geometry.BSDFSample{Wi: wi, Weight: f.MulS(math.Abs(wil.Z) / pdf), PDF: pdf}
+	return true
+}
+
+func (p Principled) Albedo() vecmath.Color {
+	return p.baseColor
+}
+
+func (p Principled) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	onb := vecmath.NewTangentONB(hr.N, hr.Tangent)
+	return p.eval(wo, wi, onb.ToLocal(wo), onb.ToLocal(wi), hr)
+}
+
+func (p Principled) eval(wo, wi, wol, wil vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	var f vecmath.Color
+	if wol.Z <= 0 {
+		return f
+	}
+
+	if wil.Z > 0 {
+		wm := wol.Add(wil).Unit()
+
+		// diffuse + sheen
+		if wd := p.diffuseWeight(); wd > 0 {
+			f = f.Add(p.baseColor.MulS(wd / math.Pi))
+			if p.sheen > 0 {
+				tint := vecmath.Color{X: 1, Y: 1, Z: 1}
+				if lum := p.baseColor.Luminance(); lum > 0 {
+					tint = p.baseColor.MulS(1 / lum)
+				}
+				csheen := vecmath.Color{X: 1, Y: 1, Z: 1}.MulS(1 - p.sheenTint).Add(tint.MulS(p.sheenTint))
+				x := 1 - wil.Dot(wm)
+				f = f.Add(csheen.MulS(wd * p.sheen * x * x * x * x * x))
+			}
+		}
+
+		// specular
+		if !p.spec.smooth() {
+			dg := p.spec.d(wm) * p.spec.g2(wol, wil) / (4 * wol.Z * wil.Z)
+			f = f.Add(p.specFresnel(wol.Dot(wm)).MulS(dg))
+		}
+
+		// clearcoat
+		if p.clearcoat > 0 {
+			dg := p.coat.d(wm) * p.coat.g2(wol, wil) / (4 * wol.Z * wil.Z)
+			fc := p.coatFresnel(wol.Dot(wm))
+			f = f.Add(vecmath.Color{X: fc, Y: fc, Z: fc}.MulS(dg))
+		}
+	}
+
+	// transmission
+	if wt := (1 - p.metallic) * p.transmission; wt > 0 {
+		f = f.Add(p.glass.Eval(wo, wi, hr).MulS(wt))
+	}
+
+	return f
+}
+
+func (p Principled) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	var (
+		onb = vecmath.NewTangentONB(hr.N, hr.Tangent)
+		wol = onb.ToLocal(wo)
+	)
+	probs, ok := p.lobeProbs(wol.Z)
+	if wol.Z <= 0 || !ok {
+		return 0
+	}
+	return p.pdf(wo, wi, wol, onb.ToLocal(wi), hr, probs)
+}
+
+func (p Principled) pdf(wo, wi, wol, wil vecmath.Vec3, hr geometry.HitRecord, probs [numLobes]float64) float64 {
+	var pdf float64
+
+	if wil.Z > 0 {
+		pdf += probs[lobeDiffuse] * wil.Z / math.Pi
+		if wm := wol.Add(wil).Unit(); wol.Dot(wm) > 0 {
+			if !p.spec.smooth() {
+				pdf += probs[lobeSpecular] * p.spec.dVisible(wol, wm) / (4 * wol.Dot(wm))
+			}
+			pdf += probs[lobeCoat] * p.coat.dVisible(wol, wm) / (4 * wol.Dot(wm))
+		}
+	}
+	if probs[lobeGlass] > 0 {
+		pdf += probs[lobeGlass] * p.glass.PDF(wo, wi, hr)
+	}
+
+	return pdf
+}
@@ -0,0 +1,46 @@
+package material
+
+import (
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestPrincipledSampleConsistency(t *testing.T) {
+	wo := vecmath.Vec3{X: 0.4, Y: 0.1, Z: 1}.Unit()
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+
+	for name, m := range map[string]Principled{
+		"plastic":   NewPrincipled(vecmath.Color{X: 0.8, Y: 0.2, Z: 0.2}, Roughness(0.3)),
+		"metal":     NewPrincipled(vecmath.Color{X: 0.9, Y: 0.6, Z: 0.3}, Metallic(1), Roughness(0.4)),
+		"cloth":     NewPrincipled(vecmath.Color{X: 0.2, Y: 0.3, Z: 0.8}, Roughness(0.9), Sheen(1, 0.5)),
+		"carpaint":  NewPrincipled(vecmath.Color{X: 0.6, Y: 0, Z: 0}, Metallic(0.5), Roughness(0.5), Clearcoat(1, 0.1)),
+		"frosted":   NewPrincipled(vecmath.Color{X: 1, Y: 1, Z: 1}, Roughness(0.3), Transmission(1, 1.5)),
+		"halfglass": NewPrincipled(vecmath.Color{X: 0.5, Y: 0.9, Z: 0.5}, Roughness(0.2), Transmission(0.5, 1.33)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			checkSampleConsistency(t, m, wo, hr)
+		})
+	}
+}
+
+func TestPrincipledSmoothMetalIsMirror(t *testing.T) {
+	m := NewPrincipled(vecmath.Color{X: 1, Y: 1, Z: 1}, Metallic(1), Roughness(0))
+	hr := geometry.HitRecord{N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, F: true}
+	wo := vecmath.Vec3{X: 0, Y: 1, Z: 1}.Unit()
+
+	var bs geometry.BSDFSample
+	if !m.Sample(wo, hr, &bs) {
+		t.Fatalf("expected principled sample to succeed")
+	}
+	if !bs.Specular {
+		t.Fatalf("smooth metal should sample a delta lobe")
+	}
+	if want := reflect(wo.Neg(), hr.N); !vecAlmostEqual(bs.Wi, want) {
+		t.Fatalf("wi = %#v, want %#v", bs.Wi, want)
+	}
+	if !vecAlmostEqual(bs.Weight, vecmath.Color{X: 1, Y: 1, Z: 1}) {
+		t.Fatalf("weight = %#v, want white", bs.Weight)
+	}
+}
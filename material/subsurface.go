@@ -0,0 +1,124 @@
+package material
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ geometry.Material = (*Subsurface)(nil)
+	_ geometry.Medium   = (*Subsurface)(nil)
+	_ geometry.Albedoer = (*Subsurface)(nil)
+)
+
+// DefaultSubsurfaceIOR is the index of refraction of a Subsurface's
+// boundary, typical of skin.
+const DefaultSubsurfaceIOR = 1.4
+
+// Subsurface is a translucent material, such as skin, wax or marble: a smooth
+// Dielectric boundary around an interior that scatters and absorbs light.
+// The renderer random walks paths through the interior, so the object must
+// be closed, like a Sphere.
+type Subsurface struct {
+	boundary       Dielectric
+	sigmaS, sigmaA vecmath.Color
+	g              float64
+
+	// surface color, for the albedo AOV
+	albedo vecmath.Color
+}
+
+type SubsurfaceOpt func(*Subsurface)
+
+// SubsurfaceIOR sets the boundary's index of refraction, by default
+// DefaultSubsurfaceIOR.
+func SubsurfaceIOR(ir float64) SubsurfaceOpt {
+	return func(s *Subsurface) {
+		s.boundary.ir = ir
+	}
+}
+
+// Anisotropy sets the Henyey-Greenstein asymmetry g of scattering in the
+// interior, from -1, backwards, through 0, evenly in all directions, the
+// default, to 1, forwards.
+func Anisotropy(g float64) SubsurfaceOpt {
+	return func(s *Subsurface) {
+		s.g = g
+	}
+}
+
+// NewSubsurface returns a Subsurface with the given scattering and absorption
+// coefficients per unit distance, for each color channel.
+func NewSubsurface(scattering, absorption vecmath.Color, opts ...SubsurfaceOpt) Subsurface {
+	s := Subsurface{
+		boundary: NewDielectric(vecmath.Color{X: 1, Y: 1, Z: 1}, IndexOfRefraction(DefaultSubsurfaceIOR)),
+		sigmaS:   scattering,
+		sigmaA:   absorption,
+	}
+	s.albedo = geometry.ScatteringAlbedo(scattering, absorption)
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// NewSubsurfaceAlbedo returns a Subsurface that looks albedo colored, with
+// light traveling a mean free path of mfp between scattering events, for
+// each color channel. The longer the path, the more translucent the
+// material.
+func NewSubsurfaceAlbedo(albedo, mfp vecmath.Color, opts ...SubsurfaceOpt) Subsurface {
+	var (
+		sigmaT = vecmath.Color{X: 1, Y: 1, Z: 1}.Div(mfp)
+		ss     = vecmath.Color{X: singleScattering(albedo.X), Y: singleScattering(albedo.Y), Z: singleScattering(albedo.Z)}
+		sigmaS = ss.Mul(sigmaT)
+		s      = NewSubsurface(sigmaS, sigmaT.Sub(sigmaS), opts...)
+	)
+	s.albedo = albedo
+	return s
+}
+
+// singleScattering returns the single scattering albedo that makes a random
+// walk reflect a of the light, from Chiang et al., "Practical and
+// Controllable Subsurface Scattering for Production Path Tracing" (2016).
+func singleScattering(a float64) float64 {
+	a = math.Max(0, math.Min(1, a))
+	x := 4.09712 + 4.20863*a - math.Sqrt(9.59217+41.6808*a+17.7126*a*a)
+	return 1 - x*x
+}
+
+func (s Subsurface) Sample(wo vecmath.Vec3, hr geometry.HitRecord, bs *geometry.BSDFSample) bool {
+	return s.boundary.Sample(wo, hr, bs)
+}
+
+func (Subsurface) Eval(wo, wi vecmath.Vec3, hr geometry.HitRecord) vecmath.Color {
+	return vecmath.Color{}
+}
+
+func (Subsurface) PDF(wo, wi vecmath.Vec3, hr geometry.HitRecord) float64 {
+	return 0
+}
+
+func (s Subsurface) Albedo() vecmath.Color {
+	return s.albedo
+}
+
+func (s Subsurface) Coefficients() (scattering, absorption vecmath.Color) {
+	return s.sigmaS, s.sigmaA
+}
+
+// Phase samples the Henyey-Greenstein phase function.
+func (s Subsurface) Phase(dir vecmath.Vec3, rng *vecmath.Rand) vecmath.Vec3 {
+	if math.Abs(s.g) < 1e-3 {
+		return vecmath.RandomUnitVec3(rng)
+	}
+	var (
+		g              = s.g
+		f              = (1 - g*g) / (1 - g + 2*g*rng.Float64())
+		cos            = math.Max(-1, math.Min(1, (1+g*g-f*f)/(2*g)))
+		sin            = math.Sqrt(1 - cos*cos)
+		sinPhi, cosPhi = math.Sincos(2 * math.Pi * rng.Float64())
+	)
+	return vecmath.NewONB(dir).ToWorld(vecmath.Vec3{X: sin * cosPhi, Y: sin * sinPhi, Z: cos})
+}
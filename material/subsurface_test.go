@@ -0,0 +1,53 @@
+package material
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestSubsurfaceAlbedo(t *testing.T) {
+	for _, tt := range []struct{ a, want float64 }{{0, 0}, {1, 1}} {
+		if got := singleScattering(tt.a); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("singleScattering(%v) = %v, want %v", tt.a, got, tt.want)
+		}
+	}
+	// multiple scattering brightens, so a surface albedo needs a higher
+	// single scattering albedo
+	if ss := singleScattering(0.5); ss <= 0.5 || ss >= 1 {
+		t.Errorf("singleScattering(0.5) = %v", ss)
+	}
+
+	s := NewSubsurfaceAlbedo(vecmath.Color{X: 0.8, Y: 0.5, Z: 0.2}, vecmath.Color{X: 1, Y: 0.5, Z: 0.25})
+	sigmaS, sigmaA := s.Coefficients()
+	if sigmaT := sigmaS.Add(sigmaA); !vecAlmostEqual(sigmaT, vecmath.Color{X: 1, Y: 2, Z: 4}) {
+		t.Errorf("extinction = %v, want one over the mean free path", sigmaT)
+	}
+	if a := s.Albedo(); a != (vecmath.Color{X: 0.8, Y: 0.5, Z: 0.2}) {
+		t.Errorf("Albedo = %v", a)
+	}
+
+	// channels that neither scatter nor absorb are transparent
+	if a := NewSubsurface(vecmath.Color{X: 1, Y: 0, Z: 0}, vecmath.Color{X: 1, Y: 1, Z: 0}).Albedo(); a != (vecmath.Color{X: 0.5, Y: 0, Z: 0}) {
+		t.Errorf("Albedo with a transparent channel = %v, want 0.5, 0, 0", a)
+	}
+}
+
+func TestSubsurfacePhase(t *testing.T) {
+	dir := vecmath.Vec3{X: 0, Y: 1, Z: 0}
+	for _, g := range []float64{0, 0.7, -0.5} {
+		var (
+			s   = NewSubsurface(vecmath.Color{X: 1, Y: 1, Z: 1}, vecmath.Color{}, Anisotropy(g))
+			sum float64
+		)
+		const n = 20000
+		for range n {
+			sum += s.Phase(dir, nil).Dot(dir)
+		}
+		// the mean cosine of Henyey-Greenstein is g
+		if mean := sum / n; math.Abs(mean-g) > 0.02 {
+			t.Errorf("g = %v: mean cosine %v", g, mean)
+		}
+	}
+}
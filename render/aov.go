@@ -0,0 +1,226 @@
+package render
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// AOV selects an arbitrary output variable: per-pixel data about the primary
+// ray's first hit, written alongside the beauty image for compositing and
+// denoising.
+type AOV int
+
+const (
+	AOVDepth AOV = iota
+	AOVNormal
+	AOVPosition
+	AOVAlbedo
+	AOVObjectID
+)
+
+var aovNames = map[AOV]string{
+	AOVDepth:    "depth",
+	AOVNormal:   "normal",
+	AOVPosition: "position",
+	AOVAlbedo:   "albedo",
+	AOVObjectID: "id",
+}
+
+func (a AOV) String() string {
+	if name, ok := aovNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("AOV(%d)", int(a))
+}
+
+// Channels returns the number of float channels the AOV is written with.
+func (a AOV) Channels() int {
+	switch a {
+	case AOVDepth, AOVObjectID:
+		return 1
+	default:
+		return 3
+	}
+}
+
+// ParseAOVs parses a comma-separated list of AOV names.
+func ParseAOVs(s string) ([]AOV, error) {
+	var aovs []AOV
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for a, n := range aovNames {
+			if n == name {
+				aovs = append(aovs, a)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown AOV %q", name)
+		}
+	}
+	return aovs, nil
+}
+
+// AOVs holds the output variables of one pixel, averaged over the primary
+// rays that hit something.
+type AOVs struct {
+	// Distance from the camera to the first hit, or +Inf if every ray escaped
+	Depth float64
+
+	// Shading normal at the first hit
+	Normal vecmath.Vec3
+
+	// World-space position of the first hit
+	Position vecmath.Point3
+
+	// Reflectance of the first hit's Material, or the background color for
+	// escaped rays
+	Albedo vecmath.Color
+
+	// ID of the first object hit by the pixel's first sample, 0 for none.
+	// See geometry.Identify.
+	ID int
+}
+
+// Value returns the channels of a.
+func (v AOVs) Value(a AOV) []float64 {
+	switch a {
+	case AOVDepth:
+		return []float64{v.Depth}
+	case AOVNormal:
+		return []float64{v.Normal.X, v.Normal.Y, v.Normal.Z}
+	case AOVPosition:
+		return []float64{v.Position.X, v.Position.Y, v.Position.Z}
+	case AOVAlbedo:
+		return []float64{v.Albedo.X, v.Albedo.Y, v.Albedo.Z}
+	case AOVObjectID:
+		return []float64{float64(v.ID)}
+	default:
+		panic("unexpected AOV")
+	}
+}
+
+// aovAccumulator averages the first hits of a pixel's samples.
+type aovAccumulator struct {
+	n, hits          int
+	depth            float64
+	normal, position vecmath.Vec3
+	albedo           vecmath.Color
+	id               int
+}
+
+func (acc *aovAccumulator) add(r geometry.Ray, hr geometry.HitRecord) {
+	acc.n++
+	if hr.M == nil {
+		acc.albedo = acc.albedo.Add(background(r))
+		return
+	}
+
+	if acc.n == 1 {
+		acc.id = hr.ID
+	}
+	acc.hits++
+	acc.depth += hr.T * r.Dir.Len()
+	acc.normal = acc.normal.Add(hr.N)
+	acc.position = acc.position.Add(hr.P)
+	if a, ok := hr.M.(geometry.Albedoer); ok {
+		acc.albedo = acc.albedo.Add(a.Albedo())
+	}
+}
+
+func (acc aovAccumulator) resolve() AOVs {
+	v := AOVs{Depth: math.Inf(1), ID: acc.id}
+	if acc.n > 0 {
+		v.Albedo = acc.albedo.DivS(float64(acc.n))
+	}
+	if acc.hits > 0 {
+		h := float64(acc.hits)
+		v.Depth = acc.depth / h
+		v.Position = acc.position.DivS(h)
+		if !acc.normal.NearZero() {
+			v.Normal = acc.normal.Unit()
+		}
+	}
+	return v
+}
+
+// FloatImage is a linear floating point image with one or more channels per
+// pixel. Row 0 is the bottom of the image, matching Coords.
+type FloatImage struct {
+	Width, Height, Channels int
+	Pix                     []float32
+}
+
+func NewFloatImage(width, height, channels int) *FloatImage {
+	return &FloatImage{width, height, channels, make([]float32, width*height*channels)}
+}
+
+// Set stores the channels of pixel (i, j).
+func (img *FloatImage) Set(i, j int, v ...float64) {
+	off := (j*img.Width + i) * img.Channels
+	for c := 0; c < img.Channels && c < len(v); c++ {
+		img.Pix[off+c] = float32(v[c])
+	}
+}
+
+// At returns the channels of pixel (i, j).
+func (img *FloatImage) At(i, j int) []float32 {
+	off := (j*img.Width + i) * img.Channels
+	return img.Pix[off : off+img.Channels]
+}
+
+// Color returns the first three channels of pixel (i, j).
+func (img *FloatImage) Color(i, j int) vecmath.Color {
+	return pixelVec(img.At(i, j))
+}
+
+// RGBA converts the first three channels to an 8-bit image, tone mapped like
+// the PPM output. Image rows run top to bottom, so the image is flipped.
+func (img *FloatImage) RGBA() *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, img.Width, img.Height))
+	for j := 0; j < img.Height; j++ {
+		for i := 0; i < img.Width; i++ {
+			rgb := img.Color(i, j).RGB(1)
+			out.SetRGBA(i, img.Height-1-j, color.RGBA{uint8(rgb.R), uint8(rgb.G), uint8(rgb.B), 255})
+		}
+	}
+	return out
+}
+
+// WritePFM encodes the image as a little-endian Portable Float Map. Only 1
+// and 3 channel images are supported.
+func (img *FloatImage) WritePFM(w io.Writer) error {
+	var magic string
+	switch img.Channels {
+	case 1:
+		magic = "Pf"
+	case 3:
+		magic = "PF"
+	default:
+		return fmt.Errorf("PFM does not support %d channels", img.Channels)
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "%s\n%d %d\n-1.0\n", magic, img.Width, img.Height); err != nil {
+		return err
+	}
+	// PFM stores rows bottom to top, which is our layout already
+	if err := binary.Write(bw, binary.LittleEndian, img.Pix); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func vecAlmostEqual(a, b vecmath.Vec3) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
+
+func TestParseAOVs(t *testing.T) {
+	got, err := ParseAOVs("depth, albedo,id")
+	if err != nil {
+		t.Fatalf("ParseAOVs: %v", err)
+	}
+	want := []AOV{AOVDepth, AOVAlbedo, AOVObjectID}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAOVs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ParseAOVs = %v, want %v", got, want)
+		}
+	}
+
+	if _, err := ParseAOVs("depth,bogus"); err == nil {
+		t.Fatalf("expected error for unknown AOV")
+	}
+}
+
+func TestAOVAccumulator(t *testing.T) {
+	var acc aovAccumulator
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: 0, Z: -2}}
+	hr := geometry.HitRecord{P: vecmath.Point3{X: 0, Y: 0, Z: -1}, N: vecmath.Vec3{X: 0, Y: 0, Z: 1}, T: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.2, Y: 0.4, Z: 0.6}), ID: 7}
+
+	acc.add(r, hr)
+	acc.add(r, hr)
+	v := acc.resolve()
+
+	if !almostEqual(v.Depth, 1) {
+		t.Fatalf("depth = %v, want 1", v.Depth)
+	}
+	if !vecAlmostEqual(v.Normal, hr.N) || !vecAlmostEqual(v.Position, hr.P) {
+		t.Fatalf("normal/position = %#v/%#v, want %#v/%#v", v.Normal, v.Position, hr.N, hr.P)
+	}
+	if !vecAlmostEqual(v.Albedo, vecmath.Color{X: 0.2, Y: 0.4, Z: 0.6}) {
+		t.Fatalf("albedo = %#v", v.Albedo)
+	}
+	if v.ID != 7 {
+		t.Fatalf("ID = %d, want 7", v.ID)
+	}
+
+	var miss aovAccumulator
+	miss.add(r, geometry.HitRecord{})
+	if v := miss.resolve(); !math.IsInf(v.Depth, 1) || v.ID != 0 {
+		t.Fatalf("escaped ray AOVs = %+v", v)
+	}
+}
+
+func TestFloatImageWritePFM(t *testing.T) {
+	img := NewFloatImage(2, 1, 1)
+	img.Set(1, 0, 0.5)
+
+	var buf bytes.Buffer
+	if err := img.WritePFM(&buf); err != nil {
+		t.Fatalf("WritePFM: %v", err)
+	}
+	header := "Pf\n2 1\n-1.0\n"
+	if got := buf.String()[:len(header)]; got != header {
+		t.Fatalf("header = %q, want %q", got, header)
+	}
+	if buf.Len() != len(header)+2*4 {
+		t.Fatalf("PFM size = %d, want %d", buf.Len(), len(header)+8)
+	}
+
+	if err := NewFloatImage(1, 1, 2).WritePFM(&buf); err == nil {
+		t.Fatalf("expected error for 2 channel PFM")
+	}
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ ApertureShape = ApertureDisk{}
+	_ ApertureShape = AperturePolygon{}
+	_ ApertureShape = (*ApertureMask)(nil)
+)
+
+// ApertureShape is the shape of a lens opening, which out-of-focus highlights
+// take on. Sample returns a uniformly distributed point of the shape, scaled
+// to the lens radius: within [-1, 1] on both axes, drawn from rng.
+type ApertureShape interface {
+	Sample(rng *vecmath.Rand) (x, y float64)
+}
+
+// ApertureDisk is a perfectly round aperture.
+type ApertureDisk struct{}
+
+func (ApertureDisk) Sample(rng *vecmath.Rand) (x, y float64) {
+	p := vecmath.RandomVec3InUnitDisk(rng)
+	return p.X, p.Y
+}
+
+// AperturePolygon is the regular polygon left open by Blades straight
+// diaphragm blades, rotated counterclockwise by Rotation degrees. Fewer than
+// three blades make a round aperture.
+type AperturePolygon struct {
+	Blades   int
+	Rotation float64
+}
+
+func (a AperturePolygon) Sample(rng *vecmath.Rand) (x, y float64) {
+	if a.Blades < 3 {
+		return ApertureDisk{}.Sample(rng)
+	}
+
+	// pick one of the equal triangles fanning out from the center, then a
+	// uniform point in it
+	var (
+		step   = 2 * math.Pi / float64(a.Blades)
+		theta  = a.Rotation*(math.Pi/180.0) + step*float64(rng.IntN(a.Blades))
+		s0, c0 = math.Sincos(theta)
+		s1, c1 = math.Sincos(theta + step)
+		r      = math.Sqrt(rng.Float64())
+		b      = rng.Float64()
+	)
+	return r * ((1-b)*c0 + b*c1), r * ((1-b)*s0 + b*s1)
+}
+
+// ApertureMask is an aperture drawn as an image: brighter pixels let through
+// more light. The image is centered on the lens and its longer side spans the
+// lens diameter.
+type ApertureMask struct {
+	width, height int
+	cdf           []float64 // cumulative brightness, row-major from the top
+}
+
+// NewApertureMask builds an ApertureMask from the brightness of img.
+func NewApertureMask(img image.Image) (*ApertureMask, error) {
+	var (
+		b   = img.Bounds()
+		m   = &ApertureMask{width: b.Dx(), height: b.Dy(), cdf: make([]float64, 0, b.Dx()*b.Dy())}
+		sum float64
+	)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sum += float64(color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y)
+			m.cdf = append(m.cdf, sum)
+		}
+	}
+	if sum == 0 {
+		return nil, errors.New("aperture mask is black")
+	}
+	return m, nil
+}
+
+func (m *ApertureMask) Sample(rng *vecmath.Rand) (x, y float64) {
+	var (
+		u     = rng.Float64() * m.cdf[len(m.cdf)-1]
+		k     = sort.Search(len(m.cdf), func(i int) bool { return m.cdf[i] > u })
+		px    = float64(k%m.width) + rng.Float64()
+		py    = float64(k/m.width) + rng.Float64()
+		scale = 2 / float64(max(m.width, m.height))
+	)
+	// image rows run downwards, the lens's y axis upwards
+	return (px - float64(m.width)/2) * scale, (float64(m.height)/2 - py) * scale
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestAperturePolygonInsideBlades(t *testing.T) {
+	for _, a := range []AperturePolygon{{Blades: 4}, {Blades: 6, Rotation: 15}, {Blades: 9, Rotation: -40}} {
+		var (
+			step       = 2 * math.Pi / float64(a.Blades)
+			apothem    = math.Cos(step / 2)
+			sumX, sumY float64
+		)
+		const n = 20000
+		for k := 0; k < n; k++ {
+			x, y := a.Sample(nil)
+			sumX += x
+			sumY += y
+			// inside every blade edge, whose normals point between vertices
+			for e := 0; e < a.Blades; e++ {
+				s, c := math.Sincos(a.Rotation*math.Pi/180 + step*(float64(e)+0.5))
+				if x*c+y*s > apothem+1e-9 {
+					t.Fatalf("%+v: sample (%v, %v) outside edge %d", a, x, y, e)
+				}
+			}
+		}
+		if math.Abs(sumX/n) > 0.02 || math.Abs(sumY/n) > 0.02 {
+			t.Errorf("%+v: samples centered at (%v, %v), want the origin", a, sumX/n, sumY/n)
+		}
+	}
+}
+
+func TestApertureDiskInUnitDisk(t *testing.T) {
+	var inner int
+	const n = 10000
+	for k := 0; k < n; k++ {
+		x, y := ApertureDisk{}.Sample(nil)
+		r := math.Hypot(x, y)
+		if r > 1 {
+			t.Fatalf("sample (%v, %v) outside the unit disk", x, y)
+		}
+		if r < math.Sqrt(0.5) {
+			inner++
+		}
+	}
+	// uniform over the area, so half the samples fall within radius sqrt(1/2)
+	if f := float64(inner) / n; math.Abs(f-0.5) > 0.03 {
+		t.Errorf("%v of samples in the inner half of the area, want 0.5", f)
+	}
+}
+
+func TestApertureMask(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	img.SetGray(0, 0, color.Gray{Y: 255}) // top left only
+
+	m, err := NewApertureMask(img)
+	if err != nil {
+		t.Fatalf("NewApertureMask: %v", err)
+	}
+	for k := 0; k < 1000; k++ {
+		// the 4 wide image spans [-1, 1], so its top left pixel is
+		// [-1, -0.5] x [0, 0.5]
+		x, y := m.Sample(nil)
+		if x < -1 || x > -0.5 || y < 0 || y > 0.5 {
+			t.Fatalf("sample (%v, %v) outside the open pixel", x, y)
+		}
+	}
+
+	if _, err := NewApertureMask(image.NewGray(image.Rect(0, 0, 2, 2))); err == nil {
+		t.Errorf("NewApertureMask accepted a black image")
+	}
+}
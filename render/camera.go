@@ -0,0 +1,456 @@
+// Package render turns a camera and a scene into images: path tracing,
+// AOVs, denoising, progressive and distributed rendering, and statistics.
+package render
+
+import (
+	"context"
+	"iter"
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/spectrum"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+type Camera struct {
+	width, height  int
+	samples, depth int
+	jobs           int
+
+	// primary rays, built from view after the options are applied, for the
+	// left and right eye of a stereo camera or in projs[0] otherwise
+	view        View
+	projection  ProjectionType
+	projs       [2]Projection
+	stereo      StereoLayout
+	interocular float64
+	axis        vecmath.Vec3 // view direction
+
+	// lens imperfections, see Vignetting and ChromaticAberration
+	vignetting, aberration float64
+
+	// bounces before Russian roulette may terminate a path, or -1 to disable
+	rrDepth int
+
+	// trace a single sampled wavelength per path
+	spectral bool
+
+	// gather RayStats for every pixel
+	stats bool
+
+	// seed of the random numbers pixel samples draw, see Seed
+	seed uint64
+
+	// pixels rendered, see Crop
+	crop Tile
+
+	// per channel gain applied to every pixel, see Photo
+	exposure vecmath.Color
+
+	// the shutter opens at frame open for shutterSpeed seconds of fps frames
+	// each, and the view moves while it is open, see Motion
+	open, fps, shutterSpeed float64
+	move                    func(time float64, v View) View
+}
+
+type CameraOpt func(*Camera)
+
+// RussianRoulette randomly terminates paths after minDepth bounces, with a
+// survival probability equal to the path throughput's luminance. Surviving
+// paths are reweighted, so the estimate stays unbiased. A negative minDepth
+// disables it.
+func RussianRoulette(minDepth int) CameraOpt {
+	return func(cam *Camera) {
+		cam.rrDepth = minDepth
+	}
+}
+
+// Spectral makes each path carry a single sampled wavelength instead of RGB,
+// so wavelength-dependent materials such as dispersive Dielectrics render
+// correctly. Colors are converted to spectra on the fly and paths are
+// converted back to RGB through the CIE matching functions at the film.
+func Spectral(spectral bool) CameraOpt {
+	return func(cam *Camera) {
+		cam.spectral = spectral
+	}
+}
+
+// Project selects the camera's projection, perspective by default.
+func Project(p ProjectionType) CameraOpt {
+	return func(cam *Camera) {
+		cam.projection = p
+	}
+}
+
+// Bokeh sets the shape of the lens aperture, which shows in out-of-focus
+// highlights. The default is round.
+func Bokeh(shape ApertureShape) CameraOpt {
+	return func(cam *Camera) {
+		cam.view.Shape = shape
+	}
+}
+
+// CollectStats makes every rendered Pixel carry RayStats for its samples.
+func CollectStats(collect bool) CameraOpt {
+	return func(cam *Camera) {
+		cam.stats = collect
+	}
+}
+
+// Seed sets the seed of the random numbers the camera samples with. Every
+// pixel sample draws from a stream of its own, selected by its pixel and its
+// index, so renders with the same seed are identical however they are split
+// into passes, tiles or workers.
+func Seed(seed uint64) CameraOpt {
+	return func(cam *Camera) {
+		cam.seed = seed
+	}
+}
+
+// Motion opens the camera's shutter at frame open of an animation at fps
+// frames per second, for the shutter speed of Photo. Each sample is taken at
+// a random time while the shutter is open, carried by its rays to Moving
+// objects, and from the view move returns for that time, if move is non-nil,
+// so both blur with their motion. An fps of 0 takes every sample at open.
+func Motion(open, fps float64, move func(time float64, v View) View) CameraOpt {
+	return func(cam *Camera) {
+		cam.open, cam.fps, cam.move = open, fps, move
+	}
+}
+
+// Shutter returns the interval of frames the camera's shutter is open, see
+// Motion.
+func (cam Camera) Shutter() (open, close float64) {
+	return cam.open, cam.open + cam.shutterSpeed*cam.fps
+}
+
+// DefaultRussianRouletteDepth is the default minimum depth for RussianRoulette.
+const DefaultRussianRouletteDepth = 3
+
+func NewCamera(width, height, samples, depth, jobs int, lookfrom, lookat vecmath.Point3, vup vecmath.Vec3, vfov, aperture, focusDist float64, opts ...CameraOpt) Camera {
+	cam := Camera{
+		width:   width,
+		height:  height,
+		samples: samples,
+		depth:   depth,
+		jobs:    jobs,
+		view: View{
+			LookFrom:  lookfrom,
+			LookAt:    lookat,
+			VUp:       vup,
+			VFov:      vfov,
+			Aspect:    float64(width) / float64(height),
+			Aperture:  aperture,
+			FocusDist: focusDist,
+		},
+		rrDepth:      DefaultRussianRouletteDepth,
+		crop:         Tile{0, 0, width, height},
+		exposure:     vecmath.Color{X: 1, Y: 1, Z: 1},
+		shutterSpeed: ReferenceShutterSpeed,
+	}
+	for _, opt := range opts {
+		opt(&cam)
+	}
+	cam.project()
+	return cam
+}
+
+// projectionAt returns the projection of eye with the view moved to time,
+// see Motion.
+func (cam Camera) projectionAt(eye int, time float64) Projection {
+	if cam.move == nil {
+		return cam.projs[eye]
+	}
+	cam.view = cam.move(time, cam.view)
+	return cam.projection.New(cam.eyeView(eye))
+}
+
+// project builds the camera's projections from its view.
+func (cam *Camera) project() {
+	for eye := range cam.projs {
+		cam.projs[eye] = cam.projection.New(cam.eyeView(eye))
+	}
+	_, _, w := cam.view.basis()
+	cam.axis = w.Neg()
+}
+
+func (cam Camera) ImageWidth() int {
+	return cam.width
+}
+
+func (cam Camera) ImageHeight() int {
+	return cam.height
+}
+
+func (cam Camera) ImageSize() int {
+	return cam.ImageWidth() * cam.ImageHeight()
+}
+
+func (cam Camera) Samples() int {
+	return cam.samples
+}
+
+// WithSamples returns a copy of cam that takes n samples per pixel.
+func (cam Camera) WithSamples(n int) Camera {
+	cam.samples = n
+	return cam
+}
+
+// rayColor calculates the Color along the Ray. We define objects + colors here,
+// and return an object's color if the Ray intersects it. Otherwise, we return
+// the background color. If lambda is non-zero, the path is traced at that
+// wavelength and every channel of the result holds its spectral radiance. If
+// first is non-nil, it receives the primary ray's HitRecord, or is left with
+// a nil Material if the ray escapes.
+func (cam Camera) rayColor(r geometry.Ray, lambda float64, world *geometry.Hittables, first *geometry.HitRecord) vecmath.Color {
+	var (
+		mult = vecmath.Vec3{X: 1, Y: 1, Z: 1}
+		hr   geometry.HitRecord
+		bs   geometry.BSDFSample
+	)
+
+	// recursive version causes stack overflow
+	for n := 0; n < cam.depth; n++ {
+		r.Stats.AddRay()
+		if !world.Hit(r, 1e-3, math.MaxFloat64, &hr) {
+			// if no object hit, render background
+			r.Stats.AddTermination(geometry.TermEscaped)
+			return cam.spectrum(background(r), lambda).Mul(mult)
+		}
+
+		if n == 0 && first != nil {
+			*first = hr
+		}
+
+		// objects in the scene
+		hr.Lambda = lambda
+		if !hr.M.Sample(r.Dir.Unit().Neg(), hr, &bs) {
+			r.Stats.AddTermination(geometry.TermAbsorbed)
+			return vecmath.Color{X: 0, Y: 0, Z: 0}
+		}
+		r = geometry.Ray{Orig: hr.P, Dir: bs.Wi, Stats: r.Stats, Rand: r.Rand, Time: r.Time}
+		mult = mult.Mul(cam.spectrum(bs.Weight, lambda))
+
+		// light refracted into a scattering interior walks through it
+		if m, ok := geometry.MediumOf(hr.M); ok && hr.F && bs.Wi.Dot(hr.N) < 0 {
+			var w vecmath.Color
+			if r, w, ok = cam.walk(r, m, lambda, world, &hr); !ok {
+				r.Stats.AddTermination(geometry.TermAbsorbed)
+				return vecmath.Color{X: 0, Y: 0, Z: 0}
+			}
+			mult = mult.Mul(w)
+		}
+
+		// dark paths contribute little; terminate them early
+		if cam.rrDepth >= 0 && n >= cam.rrDepth {
+			q := math.Min(1, mult.Luminance())
+			if r.Rand.Float64() >= q {
+				r.Stats.AddTermination(geometry.TermRoulette)
+				return vecmath.Color{X: 0, Y: 0, Z: 0}
+			}
+			mult = mult.MulS(1 / q)
+		}
+	}
+
+	r.Stats.AddTermination(geometry.TermMaxDepth)
+	return vecmath.Color{X: 0, Y: 0, Z: 0}
+}
+
+// background is the color of the sky seen along r.
+func background(r geometry.Ray) vecmath.Color {
+	var (
+		dir = r.Dir.Unit()
+		a   = vecmath.Color{X: 1, Y: 1, Z: 1}       // white
+		b   = vecmath.Color{X: 0.5, Y: 0.7, Z: 1.0} // blue
+		t   = 0.5 * (dir.Y + 1.0)
+	)
+	return a.MulS(1 - t).Add(b.MulS(t)) // (1-t)*white + t*blue
+}
+
+// spectrum returns c unchanged in RGB mode, or its spectral value at lambda in
+// every channel otherwise.
+func (cam Camera) spectrum(c vecmath.Color, lambda float64) vecmath.Color {
+	if lambda == 0 {
+		return c
+	}
+	v := spectrum.RGBToSpectrum(c, lambda)
+	return vecmath.Color{X: v, Y: v, Z: v}
+}
+
+type Coords struct {
+	I, J int
+}
+
+// Tile is a rectangle of pixels [X0, X1) x [Y0, Y1), with Y increasing
+// upwards like Coords.J.
+type Tile struct {
+	X0, Y0, X1, Y1 int
+}
+
+func (t Tile) Width() int {
+	return t.X1 - t.X0
+}
+
+func (t Tile) Height() int {
+	return t.Y1 - t.Y0
+}
+
+// Size returns the number of pixels in t.
+func (t Tile) Size() int {
+	return t.Width() * t.Height()
+}
+
+// Tiles splits the crop window, by default the whole image, into tiles of at
+// most size x size pixels, top row first.
+func (cam Camera) Tiles(size int) []Tile {
+	var (
+		c     = cam.crop
+		tiles []Tile
+	)
+	for y1 := c.Y1; y1 > c.Y0; y1 -= size {
+		for x0 := c.X0; x0 < c.X1; x0 += size {
+			tiles = append(tiles, Tile{x0, max(y1-size, c.Y0), min(x0+size, c.X1), y1})
+		}
+	}
+	return tiles
+}
+
+func (cam Camera) coords() iter.Seq[Coords] {
+	return cam.tileCoords(Tile{0, 0, cam.width, cam.height})
+}
+
+func (cam Camera) tileCoords(t Tile) iter.Seq[Coords] {
+	return func(yield func(Coords) bool) {
+		for j := t.Y1 - 1; j >= t.Y0; j-- {
+			for i := t.X0; i < t.X1; i++ {
+				if !yield(Coords{i, j}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Pixel is the result of rendering one pixel: its average linear radiance
+// and the AOVs of its primary rays.
+type Pixel struct {
+	Coords
+	Color vecmath.Color
+	AOV   AOVs
+
+	// work done rendering the pixel, if the camera collects stats
+	Stats geometry.RayStats
+}
+
+// renderPixel renders the camera's samples of the pixel at coords, numbered
+// from sample number from of the pixel on, see Seed. It stops between samples
+// once ctx is done, returning a partial pixel to be dropped.
+func (cam Camera) renderPixel(ctx context.Context, world *geometry.Hittables, coords Coords, from int) Pixel {
+	var (
+		k     = uint64(coords.J*cam.width + coords.I)
+		rng   *vecmath.Rand
+		eye   int
+		u, v  float64
+		open  float64
+		shut  float64
+		time  float64
+		pixel = vecmath.Color{X: 0, Y: 0, Z: 0}
+		r     geometry.Ray
+		ok    bool
+		c     vecmath.Color
+		l     float64
+		wt    vecmath.Color
+		first geometry.HitRecord
+		aov   aovAccumulator
+		stats geometry.RayStats
+		st    *geometry.RayStats
+	)
+	if cam.stats {
+		st = &stats
+	}
+	open, shut = cam.Shutter()
+	time = open
+
+	for s := 0; s < cam.samples && ctx.Err() == nil; s++ {
+		rng = vecmath.NewRand(cam.seed, k<<32|uint64(from+s))
+		eye, u, v = cam.film(coords, rng.Float64(), rng.Float64())
+		if cam.spectral {
+			l = spectrum.SampleWavelength(rng)
+		}
+		if cam.aberration != 0 {
+			u, v, wt = cam.aberrate(u, v, l, rng)
+		}
+		if shut > open {
+			time = open + rng.Float64()*(shut-open)
+		}
+		if r, ok = cam.projectionAt(eye, time).Ray(u, v, rng); !ok {
+			continue
+		}
+		r.Stats, r.Rand, r.Time = st, rng, time
+		st.AddPrimaryRay()
+		first = geometry.HitRecord{}
+		if cam.spectral {
+			c = spectrum.SpectrumToRGB(cam.rayColor(r, l, world, &first).X, l)
+		} else {
+			c = cam.rayColor(r, 0, world, &first)
+		}
+		if cam.aberration != 0 {
+			c = c.Mul(wt)
+		}
+		if cam.vignetting != 0 {
+			c = c.MulS(cam.vignette(r))
+		}
+		pixel = pixel.Add(c)
+		aov.add(r, first)
+	}
+
+	return Pixel{coords, pixel.DivS(float64(cam.samples)).Mul(cam.exposure), aov.resolve(), stats}
+}
+
+// RenderPixels renders every pixel in the crop window, by default the whole
+// image, top row first, in floating point with AOVs.
+func (cam Camera) RenderPixels(world *geometry.Hittables) iter.Seq[Pixel] {
+	return cam.RenderPixelsContext(context.Background(), world)
+}
+
+// RenderPixelsContext is RenderPixels, but stops once ctx is done. Pixels not
+// yet finished are not yielded; check ctx.Err() to tell a cut short render
+// from a complete one.
+func (cam Camera) RenderPixelsContext(ctx context.Context, world *geometry.Hittables) iter.Seq[Pixel] {
+	return cam.RenderTileContext(ctx, world, cam.crop)
+}
+
+// RenderTile renders the pixels of t, top row first, like RenderPixels.
+func (cam Camera) RenderTile(world *geometry.Hittables, t Tile) iter.Seq[Pixel] {
+	return cam.RenderTileContext(context.Background(), world, t)
+}
+
+// RenderTileContext is RenderTile, but stops once ctx is done, like
+// RenderPixelsContext.
+func (cam Camera) RenderTileContext(ctx context.Context, world *geometry.Hittables, t Tile) iter.Seq[Pixel] {
+	return cam.renderTile(ctx, world, t, func(Coords) int { return 0 })
+}
+
+// renderTile is RenderTileContext, numbering each pixel's samples from
+// from(coords) on.
+func (cam Camera) renderTile(ctx context.Context, world *geometry.Hittables, t Tile, from func(Coords) int) iter.Seq[Pixel] {
+	return func(yield func(Pixel) bool) {
+		render := func(coords Coords) Pixel { return cam.renderPixel(ctx, world, coords, from(coords)) }
+		for p := range ParallelMapContext(ctx, cam.tileCoords(t), render, cam.jobs) {
+			// p may be cut short if ctx is done
+			if ctx.Err() != nil || !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+func (cam Camera) Render(world *geometry.Hittables) iter.Seq[vecmath.RGB] {
+	return func(yield func(vecmath.RGB) bool) {
+		for p := range cam.RenderPixels(world) {
+			if !yield(p.Color.RGB(1)) {
+				return
+			}
+		}
+	}
+}
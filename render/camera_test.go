@@ -0,0 +1,117 @@
+package render
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestCameraCoordsCoverageAndBounds(t *testing.T) {
+	cam := NewCamera(4, 3, 1, 1, 1,
+		vecmath.Point3{X: 0, Y: 0, Z: 0},
+		vecmath.Point3{X: 0, Y: 0, Z: -1},
+		vecmath.Vec3{X: 0, Y: 1, Z: 0},
+		90,
+		0,
+		1,
+	)
+
+	seen := make(map[Coords]bool)
+	count := 0
+
+	for c := range cam.coords() {
+		if c.I < 0 || c.I >= cam.ImageWidth() {
+			t.Fatalf("coord i out of range: %d", c.I)
+		}
+		if c.J < 0 || c.J >= cam.ImageHeight() {
+			t.Fatalf("coord j out of range: %d", c.J)
+		}
+
+		if seen[c] {
+			t.Fatalf("duplicate coord: %+v", c)
+		}
+		seen[c] = true
+		count++
+	}
+
+	want := cam.ImageWidth() * cam.ImageHeight()
+	if count != want {
+		t.Fatalf("coords count = %d, want %d", count, want)
+	}
+
+	// Optional sanity check on ordering: j should start at height-1 and decrease
+	prevJ := cam.ImageHeight()
+	first := true
+	for j := cam.ImageHeight() - 1; j >= 0; j-- {
+		for i := 0; i < cam.ImageWidth(); i++ {
+			c := Coords{i, j}
+			if !seen[c] {
+				t.Fatalf("expected coord %+v to be present", c)
+			}
+			if first {
+				first = false
+			} else if j > prevJ {
+				t.Fatalf("coords not in expected top-to-bottom order: j=%d after j=%d", j, prevJ)
+			}
+			prevJ = j
+		}
+	}
+}
+
+func TestRussianRouletteUnbiased(t *testing.T) {
+	world := geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: -1000, Z: 0}, R: 1000, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+	r := geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 1, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: -1, Z: 0.2}}
+
+	mean := func(cam Camera) float64 {
+		const n = 50000
+		var sum float64
+		for i := 0; i < n; i++ {
+			sum += cam.rayColor(r, 0, &world, nil).Luminance()
+		}
+		return sum / n
+	}
+
+	newCam := func(minDepth int) Camera {
+		return NewCamera(1, 1, 1, 50, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1, RussianRoulette(minDepth))
+	}
+
+	want := mean(newCam(-1))
+	if got := mean(newCam(0)); math.Abs(got-want) > 0.02 {
+		t.Fatalf("mean with Russian roulette = %v, without = %v", got, want)
+	}
+}
+
+func TestMotionBlur(t *testing.T) {
+	var (
+		// a sphere filling the view at frame 3 moves out of it by frame 4
+		sphere = geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -5}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})}
+		at     = func(time float64) geometry.Transform {
+			return geometry.Transform{Translate: vecmath.Vec3{X: 4 * (time - 3), Y: 0, Z: 0}, Scale: vecmath.Vec3{X: 1, Y: 1, Z: 1}}
+		}
+		center = Coords{1, 1}
+	)
+	luminance := func(fps float64) float64 {
+		cam := NewCamera(3, 3, 64, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 5, 0, 5,
+			Photo(Photographic{ShutterSpeed: 1.0 / 24}), Motion(3, fps, nil), Seed(1))
+		open, shut := cam.Shutter()
+		world := geometry.NewHittables(geometry.NewMoving(sphere, at, open, shut))
+		return cam.renderPixel(context.Background(), &world, center, 0).Color.Luminance()
+	}
+
+	if open, shut := NewCamera(3, 3, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 5, 0, 5,
+		Photo(Photographic{ShutterSpeed: 1.0 / 48}), Motion(3, 24, nil)).Shutter(); open != 3 || shut != 3.5 {
+		t.Errorf("shutter open over [%v, %v], want [3, 3.5]", open, shut)
+	}
+
+	// paths that hit the sphere end there at depth 1, so only the sky shows
+	if still := luminance(0); still != 0 {
+		t.Errorf("still sphere: luminance %v, want 0", still)
+	}
+	if blurred := luminance(24); blurred <= 0 {
+		t.Errorf("moving sphere: luminance %v, want the sky showing behind it", blurred)
+	}
+}
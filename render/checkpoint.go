@@ -0,0 +1,132 @@
+package render
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// checkpointVersion is bumped whenever Checkpoint changes incompatibly.
+const checkpointVersion = 3
+
+// Checkpoint is the resumable state of a Progressive render.
+type Checkpoint struct {
+	Version int
+
+	// the settings the render was started with, see CheckpointTo
+	Settings RenderSettings
+
+	// the camera's Seed; each pixel's samples continue from its count in
+	// Counts, so a resumed render draws the same samples as one that was
+	// never stopped
+	Seed uint64
+
+	// Per-pixel accumulated radiance and sample counts, indexed like FloatImage
+	Sum    []vecmath.Color
+	Counts []uint32
+}
+
+// Checkpoint returns a snapshot of the render's state.
+func (p *Progressive) Checkpoint() Checkpoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return Checkpoint{
+		Version:  checkpointVersion,
+		Settings: p.settings,
+		Seed:     p.cam.seed,
+		Sum:      append([]vecmath.Color(nil), p.sum...),
+		Counts:   append([]uint32(nil), p.counts...),
+	}
+}
+
+// Restore continues from cp, which must have been rendered with the settings
+// given to CheckpointTo in all but Samples, drawing the remaining samples
+// from cp's Seed. The camera may ask for more samples than cp already has,
+// which adds samples to a finished render.
+func (p *Progressive) Restore(cp Checkpoint) error {
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+	if err := resumable(cp.Settings, p.settings); err != nil {
+		return err
+	}
+	if len(cp.Sum) != p.cam.ImageSize() || len(cp.Counts) != p.cam.ImageSize() {
+		return fmt.Errorf("checkpoint framebuffer does not match the %dx%d camera", p.cam.width, p.cam.height)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cam.seed = cp.Seed
+	copy(p.sum, cp.Sum)
+	copy(p.counts, cp.Counts)
+	return nil
+}
+
+// resumable reports an error if a checkpoint rendered with was differs from
+// now in any setting but Samples.
+func resumable(was, now RenderSettings) error {
+	was.Samples = now.Samples
+	if was != now {
+		return fmt.Errorf("checkpoint was rendered with settings %+v, not %+v", was, now)
+	}
+	return nil
+}
+
+func (p *Progressive) saveCheckpoint() error {
+	p.lastCheckpoint = time.Now()
+	if err := SaveCheckpoint(p.checkpoint, p.Checkpoint()); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	return nil
+}
+
+// SaveCheckpoint writes cp to path. The file is replaced atomically, so a
+// crash while saving leaves the previous checkpoint intact.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once renamed
+		_ = os.Remove(f.Name())
+	}()
+
+	if err := f.Chmod(0o644); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(cp); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadCheckpoint reads a checkpoint written by SaveCheckpoint.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	var cp Checkpoint
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cp, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	err = gob.NewDecoder(f).Decode(&cp)
+	return cp, err
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func newTestProgressive(samples int, opts ...ProgressiveOpt) *Progressive {
+	var (
+		cam   = NewCamera(4, 3, samples, 4, 2, vecmath.Point3{X: 0, Y: 0, Z: 0}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		bar   = progressbar.DefaultSilent(int64(cam.ImageSize() * cam.Samples()))
+	)
+	return NewProgressive(cam, &world, 1, bar, opts...)
+}
+
+// testSettings are the settings of newTestProgressive's render.
+func testSettings(samples int) RenderSettings {
+	return RenderSettings{Width: 4, Height: 3, Samples: samples, Depth: 4, Seed: 42}
+}
+
+func TestCheckpointResumeAddsSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.ckpt")
+
+	p := newTestProgressive(2, CheckpointTo(path, 0, testSettings(2)))
+	if err := p.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	cp, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if cp.Settings != testSettings(2) {
+		t.Fatalf("checkpoint settings = %#v, want %#v", cp.Settings, testSettings(2))
+	}
+	for i, c := range cp.Counts {
+		if c != 2 {
+			t.Fatalf("pixel %d has %d samples, want 2", i, c)
+		}
+	}
+
+	resumed := newTestProgressive(5, CheckpointTo(path, 0, testSettings(5)))
+	if err := resumed.Restore(cp); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := resumed.Samples(); got != 2 {
+		t.Fatalf("restored samples = %d, want 2", got)
+	}
+	if err := resumed.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := resumed.Samples(); got != 5 {
+		t.Fatalf("resumed samples = %d, want 5", got)
+	}
+}
+
+func TestCheckpointResumeMatchesUninterrupted(t *testing.T) {
+	whole := newTestProgressive(4)
+	whole.cam.seed = 7
+	if err := whole.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	half := newTestProgressive(2)
+	half.cam.seed = 7
+	if err := half.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// the resumed camera's own seed is replaced by the checkpoint's
+	resumed := newTestProgressive(4)
+	if err := resumed.Restore(half.Checkpoint()); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if err := resumed.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for k := range whole.sum {
+		if resumed.sum[k] != whole.sum[k] || resumed.counts[k] != whole.counts[k] {
+			t.Fatalf("pixel %d: resumed %v over %d samples, uninterrupted %v over %d", k, resumed.sum[k], resumed.counts[k], whole.sum[k], whole.counts[k])
+		}
+	}
+}
+
+func TestCheckpointRestoreMismatch(t *testing.T) {
+	cp := newTestProgressive(1, CheckpointTo("", 0, testSettings(1))).Checkpoint()
+
+	for name, change := range map[string]func(*RenderSettings){
+		"size":       func(s *RenderSettings) { s.Width = 8 },
+		"depth":      func(s *RenderSettings) { s.Depth = 10 },
+		"seed":       func(s *RenderSettings) { s.Seed = 7 },
+		"spectral":   func(s *RenderSettings) { s.Spectral = true },
+		"scene":      func(s *RenderSettings) { s.Scene = "scene.json" },
+		"projection": func(s *RenderSettings) { s.Projection = ProjectionFisheye },
+		"exposure":   func(s *RenderSettings) { s.Photo.ISO = 400 },
+		"frame rate": func(s *RenderSettings) { s.FPS = 30 },
+	} {
+		s := testSettings(1)
+		change(&s)
+		if err := newTestProgressive(1, CheckpointTo("", 0, s)).Restore(cp); err == nil {
+			t.Fatalf("%s: expected error restoring a checkpoint rendered with different settings", name)
+		}
+	}
+
+	old := cp
+	old.Version = 1
+	if err := newTestProgressive(1, CheckpointTo("", 0, testSettings(1))).Restore(old); err == nil {
+		t.Fatalf("expected error restoring a version 1 checkpoint")
+	}
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Crop restricts rendering to r, in image coordinates: pixels from the top
+// left corner, y increasing downwards. The projection is still that of the
+// full frame, so a crop lines up with the full image it was cut from.
+func Crop(r image.Rectangle) CameraOpt {
+	return func(cam *Camera) {
+		r = r.Intersect(image.Rect(0, 0, cam.width, cam.height))
+		cam.crop = Tile{r.Min.X, cam.height - r.Max.Y, r.Max.X, cam.height - r.Min.Y}
+	}
+}
+
+// CropNormalized is Crop with the corners given as fractions of the image
+// width and height. Partially covered pixels are included.
+func CropNormalized(x0, y0, x1, y1 float64) CameraOpt {
+	return func(cam *Camera) {
+		w, h := float64(cam.width), float64(cam.height)
+		Crop(image.Rect(
+			int(math.Floor(x0*w)), int(math.Floor(y0*h)),
+			int(math.Ceil(x1*w)), int(math.Ceil(y1*h))))(cam)
+	}
+}
+
+// ParseCrop parses a crop window "x0,y0,x1,y1" of top left and bottom right
+// corners, in pixels, e.g. "100,50,300,200", or as fractions of the image
+// size if any of them has a decimal point, e.g. "0.25,0.25,0.75,0.75".
+func ParseCrop(s string) (CameraOpt, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid crop window %q: want x0,y0,x1,y1", s)
+	}
+
+	var (
+		v          [4]float64
+		normalized bool
+	)
+	for k, p := range parts {
+		p = strings.TrimSpace(p)
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid crop window %q: %w", s, err)
+		}
+		if f < 0 {
+			return nil, fmt.Errorf("invalid crop window %q: negative coordinate", s)
+		}
+		v[k] = f
+		normalized = normalized || strings.Contains(p, ".")
+	}
+	if v[2] <= v[0] || v[3] <= v[1] {
+		return nil, fmt.Errorf("invalid crop window %q: empty", s)
+	}
+
+	if normalized {
+		if v[2] > 1 || v[3] > 1 {
+			return nil, fmt.Errorf("invalid crop window %q: fractions must be at most 1", s)
+		}
+		return CropNormalized(v[0], v[1], v[2], v[3]), nil
+	}
+	for _, f := range v {
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("invalid crop window %q: pixel coordinates must be integers", s)
+		}
+	}
+	return Crop(image.Rect(int(v[0]), int(v[1]), int(v[2]), int(v[3]))), nil
+}
+
+// Crop returns the pixels the camera renders, the whole image unless cropped.
+func (cam Camera) Crop() Tile {
+	return cam.crop
+}
+
+// CropRect returns Crop in image coordinates, like the argument to Crop.
+func (cam Camera) CropRect() image.Rectangle {
+	return image.Rect(cam.crop.X0, cam.height-cam.crop.Y1, cam.crop.X1, cam.height-cam.crop.Y0)
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"image"
+	"testing"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func newCropCamera(opts ...CameraOpt) Camera {
+	return NewCamera(10, 8, 1, 2, 2, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1, opts...)
+}
+
+func TestParseCrop(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want image.Rectangle
+	}{
+		{"2,1,6,4", image.Rect(2, 1, 6, 4)},
+		{"0.2, 0.125, 0.6, 0.5", image.Rect(2, 1, 6, 4)},
+		{"0.25,0,1,1", image.Rect(2, 0, 10, 8)}, // partial pixels are included
+		{"5,5,50,50", image.Rect(5, 5, 10, 8)},  // clamped to the image
+	} {
+		opt, err := ParseCrop(tt.s)
+		if err != nil {
+			t.Errorf("ParseCrop(%q): %v", tt.s, err)
+			continue
+		}
+		if got := newCropCamera(opt).CropRect(); got != tt.want {
+			t.Errorf("ParseCrop(%q) crops %v, want %v", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "1,2,3", "1,2,3,x", "4,1,2,3", "1,1,1,3", "-1,0,2,2", "0,0,1.5,0.5", "0,0,2.5,3"} {
+		if _, err := ParseCrop(s); err == nil {
+			t.Errorf("ParseCrop(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestCropRendersWindow(t *testing.T) {
+	var (
+		cam   = newCropCamera(Crop(image.Rect(2, 1, 6, 4)))
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		// image rows 1-3 from the top are framebuffer rows 6-4
+		want = Tile{2, 4, 6, 7}
+	)
+	if got := cam.Crop(); got != want {
+		t.Fatalf("Crop() = %+v, want %+v", got, want)
+	}
+
+	n := 0
+	for p := range cam.RenderPixels(&world) {
+		if p.I < want.X0 || p.I >= want.X1 || p.J < want.Y0 || p.J >= want.Y1 {
+			t.Errorf("rendered pixel %+v outside crop %+v", p.Coords, want)
+		}
+		n++
+	}
+	if n != want.Size() {
+		t.Errorf("rendered %d pixels, want %d", n, want.Size())
+	}
+
+	covered := 0
+	for _, tile := range cam.Tiles(3) {
+		if tile.X0 < want.X0 || tile.X1 > want.X1 || tile.Y0 < want.Y0 || tile.Y1 > want.Y1 {
+			t.Errorf("tile %+v outside crop %+v", tile, want)
+		}
+		covered += tile.Size()
+	}
+	if covered != want.Size() {
+		t.Errorf("tiles cover %d pixels, want %d", covered, want.Size())
+	}
+}
+
+func TestProgressiveCrop(t *testing.T) {
+	var (
+		cam   = newCropCamera(Crop(image.Rect(2, 1, 6, 4))).WithSamples(2)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		bar   = progressbar.DefaultSilent(int64(cam.Crop().Size() * cam.Samples()))
+		p     = NewProgressive(cam, &world, 1, bar)
+	)
+	if err := p.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := p.Samples(); got != 2 {
+		t.Errorf("samples = %d, want 2", got)
+	}
+	if p.counts[0] != 0 {
+		t.Errorf("pixel outside the crop window has %d samples", p.counts[0])
+	}
+}
@@ -0,0 +1,154 @@
+package render
+
+import (
+	"iter"
+	"math"
+	"runtime"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// denoiser holds the parameters of Denoise.
+type denoiser struct {
+	iterations                  int
+	sigmaColor, sigmaN, sigmaAl float64
+	jobs                        int
+}
+
+type DenoiseOpt func(*denoiser)
+
+// DenoiseIterations sets the number of À-Trous passes. Each pass doubles the
+// filter footprint, so n passes cover a (4*(2^n - 1) + 1) pixel wide window.
+func DenoiseIterations(n int) DenoiseOpt {
+	return func(d *denoiser) {
+		d.iterations = n
+	}
+}
+
+// DenoiseSigmas sets how strongly color, normal and albedo differences stop
+// the filter from blurring across edges. Smaller values preserve more detail.
+func DenoiseSigmas(color, normal, albedo float64) DenoiseOpt {
+	return func(d *denoiser) {
+		d.sigmaColor = color
+		d.sigmaN = normal
+		d.sigmaAl = albedo
+	}
+}
+
+// DenoiseJobs sets the number of rows filtered concurrently.
+func DenoiseJobs(jobs int) DenoiseOpt {
+	return func(d *denoiser) {
+		d.jobs = jobs
+	}
+}
+
+// atrousKernel is the 1D B3-spline kernel used at every scale.
+var atrousKernel = [5]float64{1.0 / 16, 1.0 / 4, 3.0 / 8, 1.0 / 4, 1.0 / 16}
+
+// Denoise filters a 3 channel color image with the edge-avoiding À-Trous
+// wavelet transform (Dammertz et al., "Edge-Avoiding À-Trous Wavelet
+// Transform for fast Global Illumination Filtering", HPG 2010), guided by the
+// albedo and normal AOVs. Color is divided by albedo before filtering and
+// multiplied back afterwards, so texture detail is preserved.
+func Denoise(color, albedo, normal *FloatImage, opts ...DenoiseOpt) *FloatImage {
+	d := denoiser{
+		iterations: 5,
+		sigmaColor: 0.5,
+		sigmaN:     0.3,
+		sigmaAl:    0.1,
+		jobs:       runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+
+	// demodulate albedo
+	irr := NewFloatImage(color.Width, color.Height, 3)
+	for j := 0; j < color.Height; j++ {
+		for i := 0; i < color.Width; i++ {
+			c, a := color.At(i, j), albedo.At(i, j)
+			for k := range 3 {
+				irr.At(i, j)[k] = c[k] / float32(math.Max(float64(a[k]), 1e-3))
+			}
+		}
+	}
+
+	for n := 0; n < d.iterations; n++ {
+		irr = d.pass(irr, albedo, normal, 1<<n)
+	}
+
+	// remodulate albedo
+	out := NewFloatImage(color.Width, color.Height, 3)
+	for j := 0; j < color.Height; j++ {
+		for i := 0; i < color.Width; i++ {
+			c, a := irr.At(i, j), albedo.At(i, j)
+			for k := range 3 {
+				out.At(i, j)[k] = c[k] * float32(math.Max(float64(a[k]), 1e-3))
+			}
+		}
+	}
+	return out
+}
+
+// pass runs one À-Trous iteration with the given step between kernel taps.
+// The color sigma shrinks with the step, as noise is removed at each scale.
+func (d denoiser) pass(in, albedo, normal *FloatImage, step int) *FloatImage {
+	var (
+		out        = NewFloatImage(in.Width, in.Height, 3)
+		sigmaColor = d.sigmaColor / float64(step)
+	)
+
+	var rows iter.Seq[int] = func(yield func(int) bool) {
+		for j := 0; j < in.Height; j++ {
+			if !yield(j) {
+				return
+			}
+		}
+	}
+
+	filterRow := func(j int) int {
+		for i := 0; i < in.Width; i++ {
+			var (
+				cp  = pixelVec(in.At(i, j))
+				np  = pixelVec(normal.At(i, j))
+				ap  = pixelVec(albedo.At(i, j))
+				sum vecmath.Vec3
+				wt  float64
+			)
+			for dy := -2; dy <= 2; dy++ {
+				y := j + dy*step
+				if y < 0 || y >= in.Height {
+					continue
+				}
+				for dx := -2; dx <= 2; dx++ {
+					x := i + dx*step
+					if x < 0 || x >= in.Width {
+						continue
+					}
+					var (
+						cq = pixelVec(in.At(x, y))
+						nq = pixelVec(normal.At(x, y))
+						aq = pixelVec(albedo.At(x, y))
+						w  = atrousKernel[dx+2] * atrousKernel[dy+2] *
+							math.Exp(-cp.Sub(cq).LenSq()/(sigmaColor*sigmaColor)-
+								np.Sub(nq).LenSq()/(d.sigmaN*d.sigmaN)-
+								ap.Sub(aq).LenSq()/(d.sigmaAl*d.sigmaAl))
+					)
+					sum = sum.Add(cq.MulS(w))
+					wt += w
+				}
+			}
+			// the center tap always has weight > 0
+			out.Set(i, j, sum.X/wt, sum.Y/wt, sum.Z/wt)
+		}
+		return j
+	}
+
+	for range ParallelMap(rows, filterRow, d.jobs) {
+	}
+	return out
+}
+
+func pixelVec(v []float32) vecmath.Vec3 {
+	return vecmath.Vec3{X: float64(v[0]), Y: float64(v[1]), Z: float64(v[2])}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func filledImage(w, h int, f func(i, j int) vecmath.Vec3) *FloatImage {
+	img := NewFloatImage(w, h, 3)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			v := f(i, j)
+			img.Set(i, j, v.X, v.Y, v.Z)
+		}
+	}
+	return img
+}
+
+func variance(img *FloatImage) float64 {
+	var sum, sumSq float64
+	n := float64(img.Width * img.Height)
+	for j := 0; j < img.Height; j++ {
+		for i := 0; i < img.Width; i++ {
+			v := float64(img.At(i, j)[0])
+			sum += v
+			sumSq += v * v
+		}
+	}
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
+
+func TestDenoiseReducesNoise(t *testing.T) {
+	const w, h = 32, 32
+	var (
+		normal = filledImage(w, h, func(int, int) vecmath.Vec3 { return vecmath.Vec3{X: 0, Y: 0, Z: 1} })
+		albedo = filledImage(w, h, func(int, int) vecmath.Vec3 { return vecmath.Vec3{X: 0.5, Y: 0.5, Z: 0.5} })
+		noisy  = filledImage(w, h, func(int, int) vecmath.Vec3 {
+			v := 0.25 + 0.1*(rand.Float64()-0.5)
+			return vecmath.Vec3{X: v, Y: v, Z: v}
+		})
+	)
+
+	got := Denoise(noisy, albedo, normal)
+	if before, after := variance(noisy), variance(got); after > before/4 {
+		t.Fatalf("variance after denoising = %v, before = %v", after, before)
+	}
+}
+
+func TestDenoisePreservesAlbedoEdges(t *testing.T) {
+	const w, h = 16, 16
+	var (
+		normal = filledImage(w, h, func(int, int) vecmath.Vec3 { return vecmath.Vec3{X: 0, Y: 0, Z: 1} })
+		albedo = filledImage(w, h, func(i, _ int) vecmath.Vec3 {
+			if i < w/2 {
+				return vecmath.Vec3{X: 0.1, Y: 0.1, Z: 0.1}
+			}
+			return vecmath.Vec3{X: 0.9, Y: 0.9, Z: 0.9}
+		})
+	)
+
+	got := Denoise(albedo, albedo, normal)
+	for _, i := range []int{w/2 - 1, w / 2} {
+		want := albedo.At(i, h/2)[0]
+		if d := got.At(i, h/2)[0] - want; d > 1e-3 || d < -1e-3 {
+			t.Fatalf("pixel %d = %v, want %v", i, got.At(i, h/2)[0], want)
+		}
+	}
+}
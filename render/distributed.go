@@ -0,0 +1,246 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// RenderSettings is everything a worker needs to rebuild the coordinator's
+// camera and scene. It is sent with every tile, so workers are stateless, and
+// recorded in checkpoints, so a resumed render rebuilds the same image.
+type RenderSettings struct {
+	Width, Height   int
+	Samples, Depth  int
+	RRDepth         int
+	Spectral        bool
+	SimpleDiffusion bool
+	Seed            int64
+	Projection      ProjectionType
+
+	// scene file that every worker can read, or empty for the random scene
+	// generated from Seed
+	Scene string
+
+	// aperture blades and their rotation in degrees, or a mask image file
+	// that every worker can read, see Bokeh
+	Blades        int
+	BladeRotation float64
+	BokehMask     string
+
+	// photographic exposure, see Photo
+	Photo Photographic
+
+	// "auto", a pixel "x,y" or an object name to focus on, or empty for a
+	// fixed focus distance
+	Focus string
+
+	// stereo layout, eye separation and convergence distance, see Stereo
+	Stereo      StereoLayout
+	Interocular float64
+	Convergence float64
+
+	// lens imperfections, see Vignetting and ChromaticAberration
+	Vignetting          float64
+	ChromaticAberration float64
+
+	// animation frame, and frames per turntable revolution or 0 for a still
+	Frame, Turntable int
+
+	// frames per second, which with the shutter speed sets how far things
+	// move while the shutter is open, see Motion
+	FPS float64
+
+	// collect RayStats, see CollectStats
+	Stats bool
+}
+
+// TileArgs asks a worker to render one tile.
+type TileArgs struct {
+	Settings RenderSettings
+	Tile     Tile
+}
+
+// TileReply holds a rendered tile's linear colors, row-major from (X0, Y0),
+// and the work done rendering it if RenderSettings.Stats is set.
+type TileReply struct {
+	Colors []vecmath.Color
+	Stats  geometry.RayStats
+}
+
+// SceneBuilder builds the camera and scene described by settings, or
+// reports why it cannot, e.g. a scene file that does not load.
+type SceneBuilder func(RenderSettings) (Camera, *geometry.Hittables, error)
+
+// Worker is the net/rpc service run by worker processes.
+type Worker struct {
+	build SceneBuilder
+
+	// the scene built for the last settings asked for; coordinators render
+	// an animation a frame at a time, so older frames are not asked for again
+	mu   sync.Mutex
+	last *builtScene
+}
+
+type builtScene struct {
+	settings RenderSettings
+	cam      Camera
+	world    *geometry.Hittables
+}
+
+func NewWorker(build SceneBuilder) *Worker {
+	return &Worker{build: build}
+}
+
+// scene returns the camera and scene for settings, building them unless they
+// were the last ones built.
+func (w *Worker) scene(s RenderSettings) (Camera, *geometry.Hittables, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.last == nil || w.last.settings != s {
+		cam, world, err := w.build(s)
+		if err != nil {
+			return Camera{}, nil, err
+		}
+		w.last = &builtScene{s, cam, world}
+	}
+	return w.last.cam, w.last.world, nil
+}
+
+// RenderTile renders args.Tile and returns its colors, or the error building
+// its scene.
+func (w *Worker) RenderTile(args TileArgs, reply *TileReply) error {
+	cam, world, err := w.scene(args.Settings)
+	if err != nil {
+		return err
+	}
+
+	t := args.Tile
+	if t.X0 < 0 || t.Y0 < 0 || t.X1 > cam.width || t.Y1 > cam.height || t.Width() <= 0 || t.Height() <= 0 {
+		return fmt.Errorf("tile %+v outside %dx%d image", t, cam.width, cam.height)
+	}
+
+	reply.Colors = make([]vecmath.Color, t.Width()*t.Height())
+	for p := range cam.RenderTile(world, t) {
+		reply.Colors[(p.J-t.Y0)*t.Width()+p.I-t.X0] = p.Color
+		reply.Stats.Merge(p.Stats)
+	}
+	return nil
+}
+
+// ServeWorker registers w and serves RPC connections from l until it is
+// closed.
+func ServeWorker(l net.Listener, w *Worker) error {
+	srv := rpc.NewServer()
+	if err := srv.Register(w); err != nil {
+		return err
+	}
+	srv.Accept(l)
+	return nil
+}
+
+// RenderDistributed splits the image described by settings into tiles and
+// renders them on the workers at addrs. Each finished tile is passed to
+// onTile, one at a time. Tiles that fail are retried on another worker, and a
+// failing worker is dropped; rendering only fails if every worker does.
+// Once ctx is done no more tiles are handed out or passed to onTile, and
+// ctx.Err() is returned.
+func RenderDistributed(ctx context.Context, settings RenderSettings, tiles []Tile, addrs []string, onTile func(Tile, TileReply)) error {
+	if len(tiles) == 0 {
+		return nil
+	}
+	if len(addrs) == 0 {
+		return errors.New("no workers")
+	}
+
+	var (
+		queue     = make(chan Tile, len(tiles))
+		remaining atomic.Int64
+		alive     atomic.Int64
+		finished  = make(chan struct{})
+		allDead   = make(chan struct{})
+		mu        sync.Mutex
+	)
+	for _, t := range tiles {
+		queue <- t
+	}
+	remaining.Store(int64(len(tiles)))
+	alive.Store(int64(len(addrs)))
+
+	for _, addr := range addrs {
+		go func() {
+			defer func() {
+				if alive.Add(-1) == 0 {
+					close(allDead)
+				}
+			}()
+
+			client, err := rpc.Dial("tcp", addr)
+			if err != nil {
+				log.Printf("warning: dropping worker %s: %v", addr, err)
+				return
+			}
+			defer func() {
+				_ = client.Close()
+			}()
+
+			for {
+				var t Tile
+				select {
+				case <-ctx.Done():
+					return
+				case <-finished:
+					return
+				case t = <-queue:
+				}
+
+				var reply TileReply
+				err := client.Call("Worker.RenderTile", TileArgs{settings, t}, &reply)
+				if err == nil && len(reply.Colors) != t.Width()*t.Height() {
+					err = fmt.Errorf("got %d pixels for tile %+v", len(reply.Colors), t)
+				}
+				if err != nil {
+					queue <- t // retry elsewhere
+					log.Printf("warning: dropping worker %s: %v", addr, err)
+					return
+				}
+
+				mu.Lock()
+				if ctx.Err() != nil {
+					mu.Unlock()
+					return
+				}
+				onTile(t, reply)
+				mu.Unlock()
+
+				if remaining.Add(-1) == 0 {
+					close(finished)
+				}
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		// wait out a tile being handed to onTile; later ones see ctx.Err()
+		mu.Lock()
+		defer mu.Unlock()
+		return ctx.Err()
+	case <-finished:
+		return nil
+	case <-allDead:
+		if remaining.Load() == 0 {
+			return nil
+		}
+		return fmt.Errorf("all workers failed with %d tiles left", remaining.Load())
+	}
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func testSceneBuilder(s RenderSettings) (Camera, *geometry.Hittables, error) {
+	var (
+		cam   = NewCamera(s.Width, s.Height, s.Samples, s.Depth, 2, vecmath.Point3{X: 0, Y: 0, Z: 0}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+	)
+	return cam, &world, nil
+}
+
+func TestRenderDistributedSurvivesDeadWorker(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		_ = ServeWorker(l, NewWorker(testSceneBuilder))
+	}()
+
+	// reserve an address, then close it so dialing it fails
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	deadAddr := dead.Addr().String()
+	dead.Close()
+
+	var (
+		settings  = RenderSettings{Width: 10, Height: 7, Samples: 1, Depth: 4}
+		cam, _, _ = testSceneBuilder(settings)
+		tiles     = cam.Tiles(4)
+		seen      = make(map[Coords]int)
+		n         int
+	)
+	err = RenderDistributed(context.Background(), settings, tiles, []string{deadAddr, l.Addr().String()}, func(tile Tile, reply TileReply) {
+		n++
+		if len(reply.Colors) != tile.Width()*tile.Height() {
+			t.Errorf("tile %+v has %d colors", tile, len(reply.Colors))
+		}
+		for c := range cam.tileCoords(tile) {
+			seen[c]++
+		}
+	})
+	if err != nil {
+		t.Fatalf("RenderDistributed: %v", err)
+	}
+	if n != len(tiles) {
+		t.Errorf("got %d tiles, want %d", n, len(tiles))
+	}
+	if len(seen) != cam.ImageSize() {
+		t.Errorf("covered %d pixels, want %d", len(seen), cam.ImageSize())
+	}
+	for c, k := range seen {
+		if k != 1 {
+			t.Errorf("pixel %+v rendered %d times", c, k)
+		}
+	}
+}
+
+func TestRenderDistributedAllWorkersDead(t *testing.T) {
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := dead.Addr().String()
+	dead.Close()
+
+	tiles := []Tile{{0, 0, 2, 2}}
+	if err := RenderDistributed(context.Background(), RenderSettings{Width: 2, Height: 2, Samples: 1, Depth: 1}, tiles, []string{addr}, func(Tile, TileReply) {}); err == nil {
+		t.Fatal("RenderDistributed succeeded with no live workers")
+	}
+}
+
+func TestWorkerKeepsLastScene(t *testing.T) {
+	var (
+		builds int
+		w      = NewWorker(func(s RenderSettings) (Camera, *geometry.Hittables, error) {
+			builds++
+			return testSceneBuilder(s)
+		})
+		a     = RenderSettings{Width: 2, Height: 2, Samples: 1, Depth: 1}
+		b     = a
+		reply TileReply
+	)
+	b.Frame = 2
+
+	for k, tt := range []struct {
+		s      RenderSettings
+		builds int
+	}{{a, 1}, {a, 1}, {b, 2}, {b, 2}, {a, 3}} {
+		if err := w.RenderTile(TileArgs{tt.s, Tile{0, 0, 2, 2}}, &reply); err != nil {
+			t.Fatalf("RenderTile: %v", err)
+		}
+		if builds != tt.builds {
+			t.Fatalf("after tile %d: built %d scenes, want %d", k, builds, tt.builds)
+		}
+	}
+}
+
+func TestWorkerReturnsBuildError(t *testing.T) {
+	var (
+		failed = errors.New("no such scene")
+		w      = NewWorker(func(RenderSettings) (Camera, *geometry.Hittables, error) {
+			return Camera{}, nil, failed
+		})
+		reply TileReply
+	)
+	if err := w.RenderTile(TileArgs{RenderSettings{Width: 2, Height: 2}, Tile{0, 0, 2, 2}}, &reply); !errors.Is(err, failed) {
+		t.Fatalf("RenderTile error = %v, want %v", err, failed)
+	}
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"cmp"
+	"math"
+
+	"github.com/mhv2109/RayTracing/spectrum"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Photographic camera settings. Exposure is relative to the sunny 16 rule,
+// f/16 at 1/100 s and ISO 100, which leaves rendered radiance unchanged, as
+// the built-in scenes are lit by a daylight sky of about unit radiance.
+const (
+	ReferenceFStop        = 16.0
+	ReferenceShutterSpeed = 1.0 / 100
+	ReferenceISO          = 100.0
+
+	// SensorHeight is the height of a full frame sensor in mm, which relates
+	// the field of view to a focal length.
+	SensorHeight = 24.0
+)
+
+// Photographic describes a camera in photographic units. Zero fields are left
+// at their reference values, see ReferenceFStop.
+type Photographic struct {
+	// FStop is the focal length over the aperture diameter. It sets the lens
+	// aperture, for a full frame sensor and scene units of meters, and so the
+	// depth of field as well as exposure. Zero keeps NewCamera's aperture.
+	FStop float64
+
+	// ShutterSpeed is the exposure time in seconds. With Motion, it is also
+	// how long objects and the camera move during a frame.
+	ShutterSpeed float64
+
+	ISO float64
+
+	// WhiteBalance is the color temperature in kelvin rendered as white, or
+	// zero for none.
+	WhiteBalance float64
+}
+
+// Exposure returns the factor the settings scale radiance by, relative to
+// the reference settings.
+func (p Photographic) Exposure() float64 {
+	var (
+		n   = cmp.Or(p.FStop, ReferenceFStop)
+		t   = cmp.Or(p.ShutterSpeed, ReferenceShutterSpeed)
+		iso = cmp.Or(p.ISO, ReferenceISO)
+	)
+	return (t / ReferenceShutterSpeed) * (iso / ReferenceISO) * (ReferenceFStop * ReferenceFStop) / (n * n)
+}
+
+// FocalLength returns the focal length in mm of a lens with the vertical
+// field of view vfov, in degrees, on a full frame sensor.
+func FocalLength(vfov float64) float64 {
+	return SensorHeight / (2 * math.Tan(vfov*(math.Pi/180.0)/2))
+}
+
+// Photo sets the camera's aperture, shutter speed, exposure and white balance
+// from p. The gains are applied to every Pixel's color, ahead of tone
+// mapping.
+func Photo(p Photographic) CameraOpt {
+	return func(cam *Camera) {
+		if p.FStop > 0 {
+			// mm to scene meters
+			cam.view.Aperture = FocalLength(cam.view.VFov) / p.FStop / 1000
+		}
+		cam.shutterSpeed = cmp.Or(p.ShutterSpeed, ReferenceShutterSpeed)
+		cam.exposure = vecmath.Color{X: 1, Y: 1, Z: 1}.MulS(p.Exposure())
+		if p.WhiteBalance > 0 {
+			cam.exposure = cam.exposure.Mul(spectrum.WhiteBalance(p.WhiteBalance))
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestPhotographicExposure(t *testing.T) {
+	for _, tt := range []struct {
+		p    Photographic
+		want float64
+	}{
+		{Photographic{}, 1},
+		{Photographic{FStop: 16, ShutterSpeed: 1.0 / 100, ISO: 100}, 1},
+		{Photographic{ShutterSpeed: 1.0 / 50}, 2},
+		{Photographic{ISO: 400}, 4},
+		{Photographic{FStop: 8}, 4},
+		{Photographic{FStop: 8, ShutterSpeed: 1.0 / 400}, 1}, // same exposure value
+	} {
+		if got := tt.p.Exposure(); !almostEqual(got, tt.want) {
+			t.Errorf("%+v.Exposure() = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestPhoto(t *testing.T) {
+	newCam := func(opts ...CameraOpt) Camera {
+		return NewCamera(4, 3, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0.5, 1, opts...)
+	}
+
+	if cam := newCam(Photo(Photographic{ISO: 200})); cam.view.Aperture != 0.5 || !vecAlmostEqual(cam.exposure, vecmath.Color{X: 2, Y: 2, Z: 2}) {
+		t.Errorf("ISO 200: aperture %v, exposure %v", cam.view.Aperture, cam.exposure)
+	}
+
+	// a 90 degree field of view is a 12 mm lens, so f/2 is 6 mm across
+	cam := newCam(Photo(Photographic{FStop: 2, WhiteBalance: 3200}))
+	if !almostEqual(FocalLength(90), 12) || !almostEqual(cam.view.Aperture, 0.006) {
+		t.Errorf("f/2: focal length %v mm, aperture %v m", FocalLength(90), cam.view.Aperture)
+	}
+	// balancing warm light cuts red against blue
+	if e := cam.exposure; e.X >= e.Z || math.Abs(e.Y/64-1) > 0.5 {
+		t.Errorf("f/2 at 3200 K: exposure %v", e)
+	}
+}
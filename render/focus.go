@@ -0,0 +1,55 @@
+package render
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// FocusOn returns a copy of cam focused on the plane through p facing the
+// camera.
+func (cam Camera) FocusOn(p vecmath.Point3) Camera {
+	_, _, w := cam.view.basis()
+	cam.view.FocusDist = p.Sub(cam.view.LookFrom).Dot(w.Neg())
+	cam.project()
+	return cam
+}
+
+// AutoFocus returns a copy of cam focused on whatever in world is seen at
+// the center of the image. If nothing is, cam is returned unchanged with
+// false.
+func (cam Camera) AutoFocus(world *geometry.Hittables) (Camera, bool) {
+	return cam.focusThrough(world, cam.view, 0.5, 0.5)
+}
+
+// AutoFocusPixel is AutoFocus through the center of pixel (x, y), in image
+// coordinates from the top left like Crop. Stereo cameras focus through the
+// eye that sees the pixel.
+func (cam Camera) AutoFocusPixel(world *geometry.Hittables, x, y int) (Camera, bool) {
+	eye, s, t := cam.film(Coords{x, cam.height - 1 - y}, 0.5, 0.5)
+	return cam.focusThrough(world, cam.eyeView(eye), s, t)
+}
+
+// focusThrough focuses on the first hit of the ray through film position
+// (s, t) of view.
+func (cam Camera) focusThrough(world *geometry.Hittables, view View, s, t float64) (Camera, bool) {
+	// a pinhole, so the ray goes exactly through the film position
+	view.Aperture = 0
+
+	var (
+		r, ok = cam.projection.New(view).Ray(s, t, nil)
+		hr    geometry.HitRecord
+	)
+	if !ok || !world.Hit(r, 0.001, math.MaxFloat64, &hr) {
+		return cam, false
+	}
+	return cam.FocusOn(hr.P), true
+}
+
+// FocusOnObject returns a copy of cam focused on the center of obj's
+// bounding box.
+func (cam Camera) FocusOnObject(obj geometry.Hittable) Camera {
+	b := obj.BoundingBox()
+	return cam.FocusOn(b.Min.Add(b.Max).DivS(2))
+}
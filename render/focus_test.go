@@ -0,0 +1,45 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestAutoFocus(t *testing.T) {
+	var (
+		cam    = NewCamera(9, 9, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 60, 0.2, 10)
+		sphere = geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -3}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})}
+		world  = geometry.NewHittables(sphere)
+	)
+
+	focused, ok := cam.AutoFocus(&world)
+	if !ok {
+		t.Fatalf("AutoFocus missed the sphere at the image center")
+	}
+	if d := focused.view.FocusDist; !almostEqual(d, 2.5) {
+		t.Errorf("focus distance = %v, want 2.5", d)
+	}
+	// the projection is rebuilt with the focal plane at the sphere's front
+	if p := focused.projs[0].(Perspective); !almostEqual(p.lowerLeftCorner.Z, -2.5) {
+		t.Errorf("focal plane at z = %v, want -2.5", p.lowerLeftCorner.Z)
+	}
+
+	// off center, the sphere curves away from the camera
+	if focused, ok := cam.AutoFocusPixel(&world, 4, 4); !ok || focused.view.FocusDist <= 2.5 || focused.view.FocusDist >= 3 {
+		t.Errorf("AutoFocusPixel(4, 4) = %v, %v", focused.view.FocusDist, ok)
+	}
+	if _, ok := cam.AutoFocusPixel(&world, 0, 0); ok {
+		t.Errorf("AutoFocusPixel found something in the empty corner")
+	}
+
+	if d := cam.FocusOnObject(sphere).view.FocusDist; !almostEqual(d, 3) {
+		t.Errorf("FocusOnObject distance = %v, want 3", d)
+	}
+	// only the depth along the view direction counts
+	if d := cam.FocusOn(vecmath.Point3{X: 1, Y: -2, Z: -4}).view.FocusDist; !almostEqual(d, 4) {
+		t.Errorf("FocusOn distance = %v, want 4", d)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	"context"
@@ -10,13 +10,24 @@ func ParallelMap[T, V any](
 	seq iter.Seq[T],
 	f func(T) V,
 	chunksize int,
+) iter.Seq[V] {
+	return ParallelMapContext(context.Background(), seq, f, chunksize)
+}
+
+// ParallelMapContext is ParallelMap, but stops early, dropping values still
+// in flight, once ctx is done.
+func ParallelMapContext[T, V any](
+	ctx context.Context,
+	seq iter.Seq[T],
+	f func(T) V,
+	chunksize int,
 ) iter.Seq[V] {
 	if chunksize <= 0 {
 		chunksize = 1
 	}
 
 	return func(yield func(V) bool) {
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
 		next, stop := iter.Pull(seq)
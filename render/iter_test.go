@@ -1,6 +1,7 @@
-package main
+package render
 
 import (
+	"context"
 	"testing"
 )
 
@@ -54,3 +55,29 @@ func TestParallelMapEarlyStop(t *testing.T) {
 		t.Fatalf("expected to consume 3 elements, got %d", count)
 	}
 }
+
+func TestParallelMapContextCancel(t *testing.T) {
+	input := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	for range ParallelMapContext[int, int](ctx, input, func(v int) int { return v * 2 }, 8) {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	}
+
+	// values already in flight may still be yielded, but no more are started
+	if count < 3 || count > 3+8 {
+		t.Fatalf("consumed %d elements after cancelling at 3", count)
+	}
+}
@@ -0,0 +1,62 @@
+package render
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Vignetting darkens the image towards its edges with the natural cos⁴
+// falloff of light reaching the film at an angle to the view direction. At
+// strength 1 the falloff is physical; 0 disables it. It follows each primary
+// ray, lens sample included, so it needs no particular projection, but rays
+// looking sideways or backwards, as in a wide fisheye or a panorama, go
+// black at full strength.
+func Vignetting(strength float64) CameraOpt {
+	return func(cam *Camera) {
+		cam.vignetting = strength
+	}
+}
+
+// ChromaticAberration gives the lens a slightly different magnification per
+// wavelength, fringing edges in the outer image: red is magnified by
+// 1+amount, blue by 1-amount and green not at all, about the image center.
+// In RGB mode each sample traces a random channel only; spectral mode shifts
+// every wavelength smoothly.
+func ChromaticAberration(amount float64) CameraOpt {
+	return func(cam *Camera) {
+		cam.aberration = amount
+	}
+}
+
+// aberrate moves film position (s, t) for a sample at wavelength lambda, or
+// an RGB channel drawn from rng if lambda is zero, and returns the channel
+// weights of the sample.
+func (cam Camera) aberrate(s, t, lambda float64, rng *vecmath.Rand) (float64, float64, vecmath.Color) {
+	var (
+		shift  float64
+		weight = vecmath.Color{X: 1, Y: 1, Z: 1}
+	)
+	if lambda != 0 {
+		// 650nm red to 450nm blue
+		shift = (lambda - 550) / 100
+	} else {
+		switch rng.IntN(3) {
+		case 0:
+			shift, weight = 1, vecmath.Color{X: 3, Y: 0, Z: 0}
+		case 1:
+			shift, weight = 0, vecmath.Color{X: 0, Y: 3, Z: 0}
+		case 2:
+			shift, weight = -1, vecmath.Color{X: 0, Y: 0, Z: 3}
+		}
+	}
+	scale := 1 + cam.aberration*shift
+	return 0.5 + (s-0.5)*scale, 0.5 + (t-0.5)*scale, weight
+}
+
+// vignette returns the fraction of light r's sample keeps.
+func (cam Camera) vignette(r geometry.Ray) float64 {
+	cos := math.Max(0, r.Dir.Unit().Dot(cam.axis))
+	return 1 - cam.vignetting*(1-cos*cos*cos*cos)
+}
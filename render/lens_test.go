@@ -0,0 +1,56 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestVignette(t *testing.T) {
+	cam := NewCamera(10, 10, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1, Vignetting(1))
+	if f := cam.vignette(geometry.Ray{Dir: vecmath.Vec3{X: 0, Y: 0, Z: -2}}); !almostEqual(f, 1) {
+		t.Errorf("on axis vignette = %v, want 1", f)
+	}
+	// 45 degrees off axis keeps cos⁴ = 1/4
+	if f := cam.vignette(geometry.Ray{Dir: vecmath.Vec3{X: 1, Y: 0, Z: -1}}); !almostEqual(f, 0.25) {
+		t.Errorf("45 degree vignette = %v, want 0.25", f)
+	}
+	if f := cam.vignette(geometry.Ray{Dir: vecmath.Vec3{X: 0, Y: 0, Z: 1}}); f != 0 {
+		t.Errorf("backwards vignette = %v, want 0", f)
+	}
+	cam.vignetting = 0.5
+	if f := cam.vignette(geometry.Ray{Dir: vecmath.Vec3{X: 1, Y: 0, Z: -1}}); !almostEqual(f, 0.625) {
+		t.Errorf("half strength vignette = %v, want 0.625", f)
+	}
+}
+
+func TestChromaticAberration(t *testing.T) {
+	cam := NewCamera(10, 10, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1, ChromaticAberration(0.1))
+
+	// spectral: red magnified, blue shrunk, about the center
+	for _, tt := range []struct{ lambda, s float64 }{{650, 1.1}, {550, 1}, {450, 0.9}} {
+		s, c, w := cam.aberrate(1, 0.5, tt.lambda, nil)
+		if !almostEqual(s-0.5, (tt.s)*0.5) || c != 0.5 || w != (vecmath.Color{X: 1, Y: 1, Z: 1}) {
+			t.Errorf("aberrate at %vnm = %v, %v, %v", tt.lambda, s, c, w)
+		}
+	}
+
+	// RGB: each sample is one channel, at three times its weight
+	var sum vecmath.Color
+	const n = 3000
+	for range n {
+		s, _, w := cam.aberrate(1, 0.5, 0, nil)
+		switch {
+		case w.X > 0 && !almostEqual(s, 1.05), w.Y > 0 && !almostEqual(s, 1), w.Z > 0 && !almostEqual(s, 0.95):
+			t.Fatalf("aberrate moved channel %v to %v", w, s)
+		}
+		sum = sum.Add(w)
+	}
+	for _, c := range []float64{sum.X, sum.Y, sum.Z} {
+		if math.Abs(c/n-1) > 0.15 {
+			t.Errorf("mean channel weights %v, want 1 each", sum.DivS(n))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// WritePPM encodes img as a plain (P3) PPM, the command's output format.
+func WritePPM(w io.Writer, img image.Image) error {
+	var (
+		bw = bufio.NewWriter(w)
+		b  = img.Bounds()
+	)
+	if _, err := fmt.Fprintf(bw, "P3\n%d %d\n255\n", b.Dx(), b.Dy()); err != nil {
+		return err
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			if _, err := fmt.Fprintln(bw, c.R, c.G, c.B); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadPPM decodes a plain (P3) or binary (P6) PPM with 8-bit samples.
+func ReadPPM(r io.Reader) (*image.RGBA, error) {
+	br := bufio.NewReader(r)
+
+	var magic string
+	if _, err := fmt.Fscan(br, &magic); err != nil {
+		return nil, fmt.Errorf("reading PPM magic: %w", err)
+	}
+	if magic != "P3" && magic != "P6" {
+		return nil, fmt.Errorf("unsupported PPM format %q", magic)
+	}
+
+	var header [3]int // width, height, maximum value
+	for k := range header {
+		if err := skipPPMComments(br); err != nil {
+			return nil, err
+		}
+		if _, err := fmt.Fscan(br, &header[k]); err != nil {
+			return nil, fmt.Errorf("reading PPM header: %w", err)
+		}
+	}
+	w, h, maxval := header[0], header[1], header[2]
+	if w <= 0 || h <= 0 || maxval <= 0 || maxval > 255 {
+		return nil, fmt.Errorf("unsupported PPM: %dx%d, maximum value %d", w, h, maxval)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if magic == "P6" {
+		// a single whitespace byte separates the header from the samples
+		if _, err := br.ReadByte(); err != nil {
+			return nil, fmt.Errorf("reading PPM header: %w", err)
+		}
+	}
+	var rgb [3]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := range rgb {
+				if magic == "P6" {
+					b, err := br.ReadByte()
+					if err != nil {
+						return nil, fmt.Errorf("reading PPM pixels: %w", err)
+					}
+					rgb[c] = int(b)
+				} else if _, err := fmt.Fscan(br, &rgb[c]); err != nil {
+					return nil, fmt.Errorf("reading PPM pixels: %w", err)
+				}
+				rgb[c] = min(rgb[c], maxval) * 255 / maxval
+			}
+			img.SetRGBA(x, y, color.RGBA{uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2]), 255})
+		}
+	}
+	return img, nil
+}
+
+// skipPPMComments skips whitespace and "#" comments up to the next token.
+func skipPPMComments(br *bufio.Reader) error {
+	for {
+		b, err := br.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+		case '#':
+			if _, err := br.ReadString('\n'); err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+		default:
+			return br.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPPMRoundTrip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(40 * x), uint8(100 * y), 7, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WritePPM(&buf, img); err != nil {
+		t.Fatalf("WritePPM: %v", err)
+	}
+	got, err := ReadPPM(&buf)
+	if err != nil {
+		t.Fatalf("ReadPPM: %v", err)
+	}
+	if !bytes.Equal(got.Pix, img.Pix) || got.Bounds() != img.Bounds() {
+		t.Errorf("round trip = %v %v, want %v %v", got.Bounds(), got.Pix, img.Bounds(), img.Pix)
+	}
+}
+
+func TestReadPPM(t *testing.T) {
+	got, err := ReadPPM(strings.NewReader("P6\n# comment\n2 1\n# another\n255\n\x01\x02\x03\x04\x05\x06"))
+	if err != nil {
+		t.Fatalf("ReadPPM P6: %v", err)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{4, 5, 6, 255}) {
+		t.Errorf("P6 pixel (1, 0) = %v", c)
+	}
+
+	got, err = ReadPPM(strings.NewReader("P3 1 1 15 15 0 5"))
+	if err != nil {
+		t.Fatalf("ReadPPM P3: %v", err)
+	}
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{255, 0, 85, 255}) {
+		t.Errorf("P3 pixel scaled from maximum 15 = %v", c)
+	}
+
+	for _, s := range []string{"P5 1 1 255 0", "P3 1 1 255 1 2", "P3 0 1 255"} {
+		if _, err := ReadPPM(strings.NewReader(s)); err == nil {
+			t.Errorf("ReadPPM(%q) succeeded, want error", s)
+		}
+	}
+}
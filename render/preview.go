@@ -0,0 +1,290 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Progressive renders a scene in passes, accumulating every pixel into a float
+// framebuffer that can be read, and checkpointed, while rendering continues.
+type Progressive struct {
+	cam   Camera
+	world *geometry.Hittables
+	pass  int
+	bar   *progressbar.ProgressBar
+
+	// checkpointing, see CheckpointTo
+	settings        RenderSettings
+	checkpoint      string
+	checkpointEvery time.Duration
+	lastCheckpoint  time.Time
+
+	// primary rays restored from a checkpoint before Run
+	restored int64
+
+	mu     sync.RWMutex
+	sum    []vecmath.Color // per pixel, indexed like FloatImage
+	counts []uint32        // samples accumulated in sum, per pixel
+	stats  geometry.RayStats
+}
+
+type ProgressiveOpt func(*Progressive)
+
+// CheckpointTo saves a Checkpoint to path at most every interval while
+// rendering, and once more when done. The render's settings s are recorded,
+// so the scene can be rebuilt on resume and Restore can check that nothing
+// else changed.
+func CheckpointTo(path string, every time.Duration, s RenderSettings) ProgressiveOpt {
+	return func(p *Progressive) {
+		p.checkpoint = path
+		p.checkpointEvery = every
+		p.settings = s
+	}
+}
+
+// NewProgressive prepares a progressive render of cam.samples samples per
+// pixel, in passes of pass samples each. Progress is reported to bar, which
+// counts primary rays.
+func NewProgressive(cam Camera, world *geometry.Hittables, pass int, bar *progressbar.ProgressBar, opts ...ProgressiveOpt) *Progressive {
+	if pass <= 0 {
+		pass = 1
+	}
+	p := &Progressive{
+		cam:            cam,
+		world:          world,
+		pass:           pass,
+		bar:            bar,
+		lastCheckpoint: time.Now(),
+		sum:            make([]vecmath.Color, cam.ImageSize()),
+		counts:         make([]uint32, cam.ImageSize()),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run renders passes until every pixel has the camera's sample count. Pixels
+// are accumulated as soon as they finish, so an interrupted pass is not lost.
+func (p *Progressive) Run() error {
+	return p.RunContext(context.Background())
+}
+
+// RunContext is Run, but stops once ctx is done, e.g. at a time limit, and
+// returns ctx.Err(). Everything accumulated so far stays in the framebuffer,
+// and is checkpointed if checkpointing.
+func (p *Progressive) RunContext(ctx context.Context) error {
+	p.restored = p.raysDone()
+	if err := p.bar.Set64(p.restored); err != nil {
+		log.Printf("warning: progress bar set failed: %v", err)
+	}
+
+	for done := p.Samples(); done < p.cam.samples; done = p.Samples() {
+		var (
+			n   = min(p.pass, p.cam.samples-done)
+			cam = p.cam.WithSamples(n)
+		)
+		for pixel := range cam.renderTile(ctx, p.world, cam.crop, p.count) {
+			k := pixel.J*cam.width + pixel.I
+			p.mu.Lock()
+			p.sum[k] = p.sum[k].Add(pixel.Color.MulS(float64(n)))
+			p.counts[k] += uint32(n)
+			p.stats.Merge(pixel.Stats)
+			p.mu.Unlock()
+
+			if err := p.bar.Add(n); err != nil {
+				// progress bar errors are non-fatal; log and continue
+				log.Printf("warning: progress bar add failed: %v", err)
+			}
+
+			if p.checkpoint != "" && time.Since(p.lastCheckpoint) >= p.checkpointEvery {
+				if err := p.saveCheckpoint(); err != nil {
+					return err
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if p.checkpoint != "" {
+		if err := p.saveCheckpoint(); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// count returns the number of samples accumulated for the pixel at coords,
+// which its next sample is numbered from.
+func (p *Progressive) count(coords Coords) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return int(p.counts[coords.J*p.cam.width+coords.I])
+}
+
+// Samples returns the number of samples per pixel completed so far, i.e. the
+// minimum over all pixels in the camera's crop window.
+func (p *Progressive) Samples() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	c := p.cam.crop
+	if c.Size() == 0 {
+		return 0
+	}
+	n := uint32(math.MaxUint32)
+	for j := c.Y0; j < c.Y1; j++ {
+		n = min(n, slices.Min(p.counts[j*p.cam.width+c.X0:j*p.cam.width+c.X1]))
+	}
+	return int(n)
+}
+
+// RayStats returns the work done by Run so far, if the camera collects
+// stats.
+func (p *Progressive) RayStats() geometry.RayStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stats
+}
+
+// raysDone returns the number of primary rays accumulated in the crop window,
+// capped per pixel at the camera's sample count to match the progress bar's
+// maximum.
+func (p *Progressive) raysDone() int64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var n int64
+	for coords := range p.cam.tileCoords(p.cam.crop) {
+		n += int64(min(int(p.counts[coords.J*p.cam.width+coords.I]), p.cam.samples))
+	}
+	return n
+}
+
+// Image returns the current framebuffer, tone mapped like the PPM output.
+func (p *Progressive) Image() *image.RGBA {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var (
+		w, h = p.cam.width, p.cam.height
+		img  = image.NewRGBA(image.Rect(0, 0, w, h))
+	)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			k := j*w + i
+			rgb := p.sum[k].RGB(float64(max(p.counts[k], 1)))
+			// framebuffer rows run bottom to top, image rows top to bottom
+			img.SetRGBA(i, h-1-j, color.RGBA{uint8(rgb.R), uint8(rgb.G), uint8(rgb.B), 255})
+		}
+	}
+	return img
+}
+
+// ProgressStats summarizes a Progressive render for the preview page.
+type ProgressStats struct {
+	Samples     int     `json:"samples"`
+	Total       int     `json:"total"`
+	PercentDone float64 `json:"percentDone"`
+	Elapsed     float64 `json:"elapsed"`
+	ETA         float64 `json:"eta"`
+
+	// primary rays traced per second
+	RaysPerSec float64 `json:"raysPerSec"`
+}
+
+func (p *Progressive) Stats() ProgressStats {
+	st := p.bar.State()
+	stats := ProgressStats{
+		Samples:     p.Samples(),
+		Total:       p.cam.samples,
+		Elapsed:     st.SecondsSince,
+		ETA:         st.SecondsLeft,
+		PercentDone: st.CurrentPercent * 100,
+	}
+	if st.SecondsSince > 0 {
+		stats.RaysPerSec = float64(st.CurrentNum-p.restored) / st.SecondsSince
+	}
+	return stats
+}
+
+// ServeMux returns the preview's HTTP handlers: an auto-refreshing page at /,
+// the current framebuffer at /frame.png and ProgressStats at /stats.
+func (p *Progressive) ServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := w.Write([]byte(previewPage)); err != nil {
+			log.Printf("warning: failed to write preview page: %v", err)
+		}
+	})
+
+	mux.HandleFunc("GET /frame.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := png.Encode(w, p.Image()); err != nil {
+			log.Printf("warning: failed to encode preview frame: %v", err)
+		}
+	})
+
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(p.Stats()); err != nil {
+			log.Printf("warning: failed to encode preview stats: %v", err)
+		}
+	})
+
+	return mux
+}
+
+const previewPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>rt preview</title>
+<style>
+body { background: #222; color: #ddd; font-family: monospace; }
+img { max-width: 100%; image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<img id="frame" src="frame.png">
+<pre id="stats"></pre>
+<script>
+const fmt = s => new Date(s * 1000).toISOString().substring(11, 19);
+async function refresh() {
+	try {
+		const s = await (await fetch("stats")).json();
+		document.getElementById("stats").textContent =
+			"samples " + s.samples + "/" + s.total +
+			"  elapsed " + fmt(s.elapsed) +
+			"  eta " + fmt(s.eta) +
+			"  " + Math.round(s.raysPerSec).toLocaleString() + " primary rays/s";
+		const next = new Image();
+		next.onload = () => { document.getElementById("frame").src = next.src; };
+		next.src = "frame.png?" + Date.now();
+	} catch (e) {}
+}
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
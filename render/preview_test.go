@@ -0,0 +1,86 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestProgressiveServe(t *testing.T) {
+	var (
+		cam   = NewCamera(8, 6, 3, 4, 2, vecmath.Point3{X: 0, Y: 0, Z: 0}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		bar   = progressbar.DefaultSilent(int64(cam.ImageSize() * cam.Samples()))
+		p     = NewProgressive(cam, &world, 2, bar)
+	)
+	if err := p.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got := p.Samples(); got != 3 {
+		t.Fatalf("samples = %d, want 3", got)
+	}
+
+	srv := httptest.NewServer(p.ServeMux())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stats")
+	if err != nil {
+		t.Fatalf("GET /stats: %v", err)
+	}
+	var stats ProgressStats
+	err = json.NewDecoder(resp.Body).Decode(&stats)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decoding stats: %v", err)
+	}
+	if stats.Samples != 3 || stats.Total != 3 {
+		t.Fatalf("stats = %+v, want 3/3 samples", stats)
+	}
+
+	resp, err = http.Get(srv.URL + "/frame.png")
+	if err != nil {
+		t.Fatalf("GET /frame.png: %v", err)
+	}
+	img, err := png.Decode(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decoding frame: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 6 {
+		t.Fatalf("frame size = %v, want 8x6", b)
+	}
+}
+
+func TestProgressiveRunContextDeadline(t *testing.T) {
+	var (
+		cam   = NewCamera(8, 6, 1_000_000, 4, 2, vecmath.Point3{X: 0, Y: 0, Z: 0}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		bar   = progressbar.DefaultSilent(int64(cam.ImageSize() * cam.Samples()))
+		p     = NewProgressive(cam, &world, 1, bar)
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := p.RunContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunContext = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RunContext took %v to stop after a 50ms deadline", elapsed)
+	}
+	if n := p.Samples(); n == 0 || n >= cam.Samples() {
+		t.Errorf("samples = %d, want some but not all %d", n, cam.Samples())
+	}
+}
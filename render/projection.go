@@ -0,0 +1,255 @@
+package render
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ Projection = (*Perspective)(nil)
+	_ Projection = (*Orthographic)(nil)
+	_ Projection = (*Fisheye)(nil)
+	_ Projection = (*Equirectangular)(nil)
+)
+
+// Projection maps positions on the film to primary rays.
+type Projection interface {
+	// Ray returns the primary ray through film position (s, t), where s and
+	// t run from 0 to 1 left to right and bottom to top, or false if the
+	// position is outside the projection, like the corners of a fisheye.
+	// Points on the lens, if any, are drawn from rng.
+	Ray(s, t float64, rng *vecmath.Rand) (geometry.Ray, bool)
+}
+
+// View places and frames a camera. Projections interpret VFov and FocusDist
+// in their own way, see ProjectionType.
+type View struct {
+	LookFrom, LookAt vecmath.Point3
+	VUp              vecmath.Vec3
+	VFov             float64 // degrees, across the image height
+	Aspect           float64 // image width over height
+	Aperture         float64
+	FocusDist        float64
+
+	// shape of the aperture, round if nil
+	Shape ApertureShape
+
+	// Eye moves the viewpoint this far to the right, along u, for one eye of
+	// a stereo pair. Convergence is the distance at which both eyes' images
+	// line up, FocusDist if zero.
+	Eye         float64
+	Convergence float64
+}
+
+// basis returns the camera's orthonormal frame: u points right, v up and w
+// backwards, away from LookAt.
+func (v View) basis() (u, up, w vecmath.Vec3) {
+	w = v.LookFrom.Sub(v.LookAt).Unit()
+	u = v.VUp.Cross(w).Unit()
+	return u, w.Cross(u), w
+}
+
+// ProjectionType selects the Projection a Camera is built with.
+type ProjectionType int
+
+const (
+	// ProjectionPerspective is a thin lens with depth of field.
+	ProjectionPerspective ProjectionType = iota
+
+	// ProjectionOrthographic casts parallel rays, framing what the
+	// perspective projection sees at the focus distance.
+	ProjectionOrthographic
+
+	// ProjectionFisheye is an equidistant fisheye, VFov across the image
+	// height, up to 360 degrees.
+	ProjectionFisheye
+
+	// ProjectionEquirectangular is a 360 by 180 degree panorama, for a 2:1
+	// image, centered on LookAt.
+	ProjectionEquirectangular
+)
+
+var projectionNames = map[ProjectionType]string{
+	ProjectionPerspective:     "perspective",
+	ProjectionOrthographic:    "orthographic",
+	ProjectionFisheye:         "fisheye",
+	ProjectionEquirectangular: "equirectangular",
+}
+
+func (p ProjectionType) String() string {
+	if name, ok := projectionNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("ProjectionType(%d)", int(p))
+}
+
+// ParseProjection parses a projection name.
+func ParseProjection(s string) (ProjectionType, error) {
+	for p, name := range projectionNames {
+		if name == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown projection %q", s)
+}
+
+// New returns the Projection of type p for v.
+func (p ProjectionType) New(v View) Projection {
+	switch p {
+	case ProjectionOrthographic:
+		return NewOrthographic(v)
+	case ProjectionFisheye:
+		return NewFisheye(v)
+	case ProjectionEquirectangular:
+		return NewEquirectangular(v)
+	default:
+		return NewPerspective(v)
+	}
+}
+
+// Perspective is a thin lens camera, focused at View.FocusDist, with an
+// aperture of View.Shape.
+type Perspective struct {
+	origin, lowerLeftCorner vecmath.Point3
+	horiz, vert, u, v       vecmath.Vec3
+	lensRadius              float64
+	shape                   ApertureShape
+}
+
+func NewPerspective(view View) Perspective {
+	var (
+		theta      = view.VFov * (math.Pi / 180.0)
+		h          = math.Tan(theta / 2)
+		viewHeight = 2.0 * h
+		viewWidth  = view.Aspect * viewHeight
+
+		u, v, w = view.basis()
+
+		origin = view.LookFrom
+		horiz  = u.MulS(viewWidth).MulS(view.FocusDist)
+		vert   = v.MulS(viewHeight).MulS(view.FocusDist)
+		llc    = origin.Sub(horiz.DivS(2)).Sub(vert.DivS(2)).Sub(w.MulS(view.FocusDist))
+
+		// an off-axis frustum: the eye moves, but its view through the
+		// window at the convergence distance does not
+		eye         = u.MulS(view.Eye)
+		convergence = cmp.Or(view.Convergence, view.FocusDist)
+	)
+	origin = origin.Add(eye)
+	llc = llc.Add(eye.MulS(1 - view.FocusDist/convergence))
+
+	shape := view.Shape
+	if shape == nil {
+		shape = ApertureDisk{}
+	}
+	return Perspective{origin, llc, horiz, vert, u, v, view.Aperture / 2, shape}
+}
+
+func (p Perspective) Ray(s, t float64, rng *vecmath.Rand) (geometry.Ray, bool) {
+	var offset vecmath.Vec3
+	if p.lensRadius > 0 {
+		x, y := p.shape.Sample(rng)
+		offset = p.u.MulS(x * p.lensRadius).Add(p.v.MulS(y * p.lensRadius))
+	}
+	return geometry.Ray{
+		Orig: p.origin.Add(offset),
+		Dir:  p.lowerLeftCorner.Add(p.horiz.MulS(s)).Add(p.vert.MulS(t)).Sub(p.origin).Sub(offset),
+	}, true
+}
+
+// Orthographic casts parallel rays from a rectangle through View.LookFrom,
+// sized like the perspective view at View.FocusDist. It has no depth of
+// field.
+type Orthographic struct {
+	lowerLeftCorner vecmath.Point3
+	horiz, vert     vecmath.Vec3
+	dir             vecmath.Vec3
+}
+
+func NewOrthographic(view View) Orthographic {
+	var (
+		viewHeight = 2 * math.Tan(view.VFov*(math.Pi/180.0)/2) * view.FocusDist
+		viewWidth  = view.Aspect * viewHeight
+
+		u, v, w = view.basis()
+
+		horiz = u.MulS(viewWidth)
+		vert  = v.MulS(viewHeight)
+	)
+	return Orthographic{view.LookFrom.Add(u.MulS(view.Eye)).Sub(horiz.DivS(2)).Sub(vert.DivS(2)), horiz, vert, w.Neg()}
+}
+
+func (o Orthographic) Ray(s, t float64, _ *vecmath.Rand) (geometry.Ray, bool) {
+	return geometry.Ray{
+		Orig: o.lowerLeftCorner.Add(o.horiz.MulS(s)).Add(o.vert.MulS(t)),
+		Dir:  o.dir,
+	}, true
+}
+
+// Fisheye is an equidistant fisheye: the angle from the view direction grows
+// linearly with the distance from the image center, reaching half of
+// View.VFov at the top and bottom edges. Film positions more than 180 degrees
+// from the view direction have no ray.
+type Fisheye struct {
+	origin  vecmath.Point3
+	u, v, w vecmath.Vec3
+	aspect  float64
+	halfFov float64
+}
+
+func NewFisheye(view View) Fisheye {
+	u, v, w := view.basis()
+	return Fisheye{view.LookFrom.Add(u.MulS(view.Eye)), u, v, w, view.Aspect, view.VFov * (math.Pi / 180.0) / 2}
+}
+
+func (f Fisheye) Ray(s, t float64, _ *vecmath.Rand) (geometry.Ray, bool) {
+	var (
+		x     = (s - 0.5) * f.aspect
+		y     = t - 0.5
+		theta = math.Hypot(x, y) / 0.5 * f.halfFov
+	)
+	if theta > math.Pi {
+		return geometry.Ray{}, false
+	}
+	var (
+		phi      = math.Atan2(y, x)
+		sin, cos = math.Sincos(theta)
+	)
+	return geometry.Ray{
+		Orig: f.origin,
+		Dir:  f.u.MulS(sin * math.Cos(phi)).Add(f.v.MulS(sin * math.Sin(phi))).Sub(f.w.MulS(cos)),
+	}, true
+}
+
+// Equirectangular maps longitude to s and latitude to t, covering every
+// direction around View.LookFrom with LookAt at the image center. With an
+// Eye offset it is an omni-directional stereo panorama: every ray starts
+// beside LookFrom, to the right of its own direction, with the offset
+// fading towards the poles to avoid swirling there.
+type Equirectangular struct {
+	origin  vecmath.Point3
+	u, v, w vecmath.Vec3
+	eye     float64
+}
+
+func NewEquirectangular(view View) Equirectangular {
+	u, v, w := view.basis()
+	return Equirectangular{view.LookFrom, u, v, w, view.Eye}
+}
+
+func (e Equirectangular) Ray(s, t float64, _ *vecmath.Rand) (geometry.Ray, bool) {
+	var (
+		sinLon, cosLon = math.Sincos((s - 0.5) * 2 * math.Pi)
+		sinLat, cosLat = math.Sincos((t - 0.5) * math.Pi)
+	)
+	// perpendicular to the direction's horizontal part, to the right
+	right := e.u.MulS(cosLon).Add(e.w.MulS(sinLon))
+	return geometry.Ray{
+		Orig: e.origin.Add(right.MulS(e.eye * cosLat)),
+		Dir:  e.u.MulS(cosLat * sinLon).Add(e.v.MulS(sinLat)).Sub(e.w.MulS(cosLat * cosLon)),
+	}, true
+}
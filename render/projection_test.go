@@ -0,0 +1,100 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// testView looks down -Z from the origin, so u = +X, v = +Y and w = +Z.
+func testView(vfov, aspect float64) View {
+	return View{
+		LookFrom:  vecmath.Point3{},
+		LookAt:    vecmath.Point3{X: 0, Y: 0, Z: -1},
+		VUp:       vecmath.Vec3{X: 0, Y: 1, Z: 0},
+		VFov:      vfov,
+		Aspect:    aspect,
+		FocusDist: 2,
+	}
+}
+
+func rayDir(t *testing.T, p Projection, s, tt float64) vecmath.Vec3 {
+	t.Helper()
+	r, ok := p.Ray(s, tt, nil)
+	if !ok {
+		t.Fatalf("no ray at (%v, %v)", s, tt)
+	}
+	return r.Dir.Unit()
+}
+
+func TestPerspectiveProjection(t *testing.T) {
+	p := NewPerspective(testView(90, 2))
+	if d := rayDir(t, p, 0.5, 0.5); !vecAlmostEqual(d, vecmath.Vec3{X: 0, Y: 0, Z: -1}) {
+		t.Errorf("center ray = %v, want -Z", d)
+	}
+	// 45 degrees up at the top edge
+	if d := rayDir(t, p, 0.5, 1); !vecAlmostEqual(d, vecmath.Vec3{X: 0, Y: math.Sqrt2 / 2, Z: -math.Sqrt2 / 2}) {
+		t.Errorf("top ray = %v", d)
+	}
+}
+
+func TestOrthographicProjection(t *testing.T) {
+	o := NewOrthographic(testView(90, 2))
+
+	center, _ := o.Ray(0.5, 0.5, nil)
+	corner, _ := o.Ray(1, 1, nil)
+	if !vecAlmostEqual(center.Dir, corner.Dir) || !vecAlmostEqual(center.Dir.Unit(), vecmath.Vec3{X: 0, Y: 0, Z: -1}) {
+		t.Errorf("directions %v and %v, want parallel along -Z", center.Dir, corner.Dir)
+	}
+	// the view is 4 high at focus distance 2, and twice as wide
+	if !vecAlmostEqual(center.Orig, vecmath.Point3{}) || !vecAlmostEqual(corner.Orig, vecmath.Point3{X: 4, Y: 2, Z: 0}) {
+		t.Errorf("origins %v and %v", center.Orig, corner.Orig)
+	}
+}
+
+func TestFisheyeProjection(t *testing.T) {
+	f := NewFisheye(testView(180, 2))
+	if d := rayDir(t, f, 0.5, 0.5); !vecAlmostEqual(d, vecmath.Vec3{X: 0, Y: 0, Z: -1}) {
+		t.Errorf("center ray = %v, want -Z", d)
+	}
+	// half the field of view at the top edge, and equidistant in between
+	if d := rayDir(t, f, 0.5, 1); !vecAlmostEqual(d, vecmath.Vec3{X: 0, Y: 1, Z: 0}) {
+		t.Errorf("top ray = %v, want +Y", d)
+	}
+	if d := rayDir(t, f, 0.5+0.125, 0.5); !vecAlmostEqual(d, vecmath.Vec3{X: math.Sqrt2 / 2, Y: 0, Z: -math.Sqrt2 / 2}) {
+		t.Errorf("ray halfway right = %v, want 45 degrees", d)
+	}
+	if _, ok := f.Ray(0, 0, nil); ok {
+		t.Errorf("corner beyond 180 degrees has a ray")
+	}
+}
+
+func TestEquirectangularProjection(t *testing.T) {
+	e := NewEquirectangular(testView(0, 2))
+	for _, tt := range []struct {
+		s, t float64
+		want vecmath.Vec3
+	}{
+		{0.5, 0.5, vecmath.Vec3{X: 0, Y: 0, Z: -1}},
+		{0.75, 0.5, vecmath.Vec3{X: 1, Y: 0, Z: 0}},
+		{0, 0.5, vecmath.Vec3{X: 0, Y: 0, Z: 1}},
+		{0.5, 1, vecmath.Vec3{X: 0, Y: 1, Z: 0}},
+	} {
+		if d := rayDir(t, e, tt.s, tt.t); !vecAlmostEqual(d, tt.want) {
+			t.Errorf("ray at (%v, %v) = %v, want %v", tt.s, tt.t, d, tt.want)
+		}
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	for p := range projectionNames {
+		got, err := ParseProjection(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseProjection(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParseProjection("cylindrical"); err == nil {
+		t.Errorf("ParseProjection accepted an unknown projection")
+	}
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/mhv2109/RayTracing/geometry"
+)
+
+// Renderer renders a scene through a Camera, either into a whole image or as
+// a stream of tiles. It is the entry point for embedding the renderer.
+type Renderer struct {
+	cam      Camera
+	world    *geometry.Hittables
+	tileSize int
+	jobs     int
+}
+
+type RendererOpt func(*Renderer)
+
+// TileSize sets the width and height, in pixels, of the tiles rendered by
+// Renderer.Tiles.
+func TileSize(size int) RendererOpt {
+	return func(r *Renderer) {
+		r.tileSize = size
+	}
+}
+
+// TileJobs sets the number of tiles rendered concurrently. Each tile is
+// rendered on a single goroutine.
+func TileJobs(jobs int) RendererOpt {
+	return func(r *Renderer) {
+		r.jobs = jobs
+	}
+}
+
+// NewRenderer returns a Renderer of world as seen by cam. By default tiles are
+// 32 pixels square and as many are rendered at once as the camera's jobs.
+func NewRenderer(cam Camera, world *geometry.Hittables, opts ...RendererOpt) *Renderer {
+	r := &Renderer{
+		cam:      cam,
+		world:    world,
+		tileSize: 32,
+		jobs:     cam.jobs,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.tileSize <= 0 {
+		r.tileSize = 32
+	}
+	return r
+}
+
+// RenderedTile is a finished Tile. Pixels are row-major, top row first, like
+// RenderTile.
+type RenderedTile struct {
+	Tile
+	Pixels []Pixel
+}
+
+// Tiles renders the image tile by tile, top row of tiles first, yielding each
+// tile once it and every tile before it are done. Stopping early cancels the
+// remaining tiles.
+func (r *Renderer) Tiles() iter.Seq[RenderedTile] {
+	renderTile := func(t Tile) RenderedTile {
+		rt := RenderedTile{t, make([]Pixel, 0, t.Width()*t.Height())}
+		for coords := range r.cam.tileCoords(t) {
+			rt.Pixels = append(rt.Pixels, r.cam.renderPixel(context.Background(), r.world, coords, 0))
+		}
+		return rt
+	}
+	return ParallelMap(slices.Values(r.cam.Tiles(r.tileSize)), renderTile, r.jobs)
+}
+
+// Render renders the image and returns its linear RGB colors. Pixels outside
+// the camera's crop window, if any, are black.
+func (r *Renderer) Render() *FloatImage {
+	img := NewFloatImage(r.cam.width, r.cam.height, 3)
+	for t := range r.Tiles() {
+		for _, p := range t.Pixels {
+			img.Set(p.I, p.J, p.Color.X, p.Color.Y, p.Color.Z)
+		}
+	}
+	return img
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func newTestRenderer(opts ...RendererOpt) *Renderer {
+	var (
+		cam   = NewCamera(10, 7, 1, 4, 2, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+		world = geometry.NewHittables(geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+	)
+	return NewRenderer(cam, &world, opts...)
+}
+
+func TestRendererTilesCoverImage(t *testing.T) {
+	var (
+		r    = newTestRenderer(TileSize(4), TileJobs(3))
+		seen = make(map[Coords]int)
+	)
+	for tile := range r.Tiles() {
+		if len(tile.Pixels) != tile.Width()*tile.Height() {
+			t.Errorf("tile %+v has %d pixels", tile.Tile, len(tile.Pixels))
+		}
+		for _, p := range tile.Pixels {
+			if p.I < tile.X0 || p.I >= tile.X1 || p.J < tile.Y0 || p.J >= tile.Y1 {
+				t.Errorf("pixel %+v outside tile %+v", p.Coords, tile.Tile)
+			}
+			seen[p.Coords]++
+		}
+	}
+	if len(seen) != 10*7 {
+		t.Errorf("covered %d pixels, want %d", len(seen), 10*7)
+	}
+	for c, n := range seen {
+		if n != 1 {
+			t.Errorf("pixel %+v rendered %d times", c, n)
+		}
+	}
+}
+
+func TestRendererRender(t *testing.T) {
+	img := newTestRenderer().Render()
+	if img.Width != 10 || img.Height != 7 || img.Channels != 3 {
+		t.Fatalf("image is %dx%dx%d, want 10x7x3", img.Width, img.Height, img.Channels)
+	}
+	// the top corners see the sky
+	if c := img.Color(0, 6); c.Z <= 0 {
+		t.Errorf("top left pixel = %v, want sky", c)
+	}
+
+	rgba := img.RGBA()
+	if b := rgba.Bounds(); b.Dx() != 10 || b.Dy() != 7 {
+		t.Errorf("RGBA bounds = %v", b)
+	}
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhv2109/RayTracing/geometry"
+)
+
+// Phase is the time spent in one stage of producing an image.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Stats collects RayStats and phase timings for a render. Methods are no-ops
+// on a nil *Stats, so instrumentation can be left in place when disabled.
+type Stats struct {
+	geometry.RayStats
+	Phases []Phase
+}
+
+// AddPhase adds d to the time spent in the named phase.
+func (st *Stats) AddPhase(name string, d time.Duration) {
+	if st == nil {
+		return
+	}
+	for i := range st.Phases {
+		if st.Phases[i].Name == name {
+			st.Phases[i].Duration += d
+			return
+		}
+	}
+	st.Phases = append(st.Phases, Phase{name, d})
+}
+
+// Since adds the time since start to the named phase.
+func (st *Stats) Since(name string, start time.Time) {
+	st.AddPhase(name, time.Since(start))
+}
+
+// AddRays merges per-pixel ray counts.
+func (st *Stats) AddRays(rs geometry.RayStats) {
+	if st != nil {
+		st.RayStats.Merge(rs)
+	}
+}
+
+// StatsReport is the JSON form of Stats.
+type StatsReport struct {
+	PrimaryRays    int64              `json:"primaryRays"`
+	Rays           int64              `json:"rays"`
+	NodeVisits     int64              `json:"nodeVisits"`
+	PrimitiveTests int64              `json:"primitiveTests"`
+	AvgPathLength  float64            `json:"avgPathLength"`
+	Terminations   map[string]int64   `json:"terminations"`
+	Phases         map[string]float64 `json:"phases"` // seconds
+	RaysPerSec     float64            `json:"raysPerSec"`
+}
+
+func (st *Stats) Report() StatsReport {
+	r := StatsReport{
+		PrimaryRays:    st.PrimaryRays,
+		Rays:           st.Rays,
+		NodeVisits:     st.NodeVisits,
+		PrimitiveTests: st.PrimitiveTests,
+		AvgPathLength:  st.AvgPathLength(),
+		Terminations:   make(map[string]int64, geometry.NumTerminations),
+		Phases:         make(map[string]float64, len(st.Phases)),
+	}
+	for t, n := range st.Terminations {
+		r.Terminations[geometry.Termination(t).String()] = n
+	}
+	for _, p := range st.Phases {
+		r.Phases[p.Name] = p.Duration.Seconds()
+		if p.Name == "render" && p.Duration > 0 {
+			r.RaysPerSec = float64(st.Rays) / p.Duration.Seconds()
+		}
+	}
+	return r
+}
+
+// WriteJSON writes the StatsReport as a single line of JSON.
+func (st *Stats) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(st.Report())
+}
+
+// WriteSummary writes a human readable table of st.
+func (st *Stats) WriteSummary(w io.Writer) error {
+	r := st.Report()
+	rows := [][2]string{
+		{"primary rays", fmt.Sprint(r.PrimaryRays)},
+		{"rays", fmt.Sprint(r.Rays)},
+		{"rays/s", fmt.Sprintf("%.0f", r.RaysPerSec)},
+		{"avg path length", fmt.Sprintf("%.3f", r.AvgPathLength)},
+		{"BVH node visits", fmt.Sprint(r.NodeVisits)},
+		{"primitive tests", fmt.Sprint(r.PrimitiveTests)},
+	}
+	for t := range geometry.NumTerminations {
+		rows = append(rows, [2]string{"paths " + t.String(), fmt.Sprint(st.Terminations[t])})
+	}
+	for _, p := range st.Phases {
+		rows = append(rows, [2]string{p.Name + " time", p.Duration.Round(time.Millisecond).String()})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1]); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
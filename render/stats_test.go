@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhv2109/RayTracing/accel"
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func renderStats(t *testing.T, opts ...CameraOpt) geometry.RayStats {
+	t.Helper()
+	var (
+		cam   = NewCamera(8, 6, 4, 5, 2, vecmath.Point3{X: 0, Y: 0, Z: 0}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1, opts...)
+		world = geometry.NewHittables(
+			geometry.Sphere{Center: vecmath.Point3{X: 0, Y: 0, Z: -1}, R: 0.5, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})},
+			geometry.Sphere{Center: vecmath.Point3{X: 0, Y: -100.5, Z: -1}, R: 100, M: material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5})})
+		bvh   = geometry.NewHittables(geometry.NewInstance(accel.NewBVH(world.Objects), geometry.Identity))
+		total geometry.RayStats
+	)
+	for p := range cam.RenderPixels(&bvh) {
+		total.Merge(p.Stats)
+	}
+	return total
+}
+
+func TestCollectStats(t *testing.T) {
+	st := renderStats(t, CollectStats(true))
+
+	if want := int64(8 * 6 * 4); st.PrimaryRays != want {
+		t.Errorf("PrimaryRays = %d, want %d", st.PrimaryRays, want)
+	}
+	var paths int64
+	for _, n := range st.Terminations {
+		paths += n
+	}
+	if paths != st.PrimaryRays {
+		t.Errorf("%d paths terminated, want one per primary ray (%d)", paths, st.PrimaryRays)
+	}
+	if st.Rays < st.PrimaryRays || st.AvgPathLength() > 5 {
+		t.Errorf("Rays = %d, avg path length %v, for %d primary rays and depth 5", st.Rays, st.AvgPathLength(), st.PrimaryRays)
+	}
+	if st.NodeVisits == 0 || st.PrimitiveTests == 0 {
+		t.Errorf("no BVH or primitive work counted through the Instance: %+v", st)
+	}
+	if st.Terminations[geometry.TermEscaped] == 0 {
+		t.Errorf("no escaped paths counted: %+v", st.Terminations)
+	}
+}
+
+func TestCollectStatsDisabled(t *testing.T) {
+	if st := renderStats(t); st != (geometry.RayStats{}) {
+		t.Errorf("stats collected without CollectStats: %+v", st)
+	}
+}
+
+func TestStatsReport(t *testing.T) {
+	st := &Stats{}
+	st.AddRays(geometry.RayStats{PrimaryRays: 2, Rays: 6, Terminations: [geometry.NumTerminations]int64{geometry.TermRoulette: 2}})
+	st.AddPhase("render", 2*time.Second)
+	st.AddPhase("render", time.Second)
+	st.AddPhase("encode", time.Second)
+
+	var buf bytes.Buffer
+	if err := st.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var r StatsReport
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if r.AvgPathLength != 3 || r.RaysPerSec != 2 || r.Phases["render"] != 3 || r.Terminations["roulette"] != 2 {
+		t.Errorf("report = %+v", r)
+	}
+
+	buf.Reset()
+	if err := st.WriteSummary(&buf); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+	for _, want := range []string{"avg path length", "paths roulette", "render time", "encode time"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	// nil Stats are a no-op
+	var none *Stats
+	none.AddPhase("render", time.Second)
+	none.AddRays(geometry.RayStats{Rays: 1})
+}
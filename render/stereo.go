@@ -0,0 +1,95 @@
+package render
+
+import "fmt"
+
+// StereoLayout arranges the two eyes of a stereo camera in one image.
+type StereoLayout int
+
+const (
+	// StereoNone is a mono camera.
+	StereoNone StereoLayout = iota
+
+	// StereoSideBySide puts the left eye in the left half of the image.
+	StereoSideBySide
+
+	// StereoTopBottom puts the left eye in the top half of the image.
+	StereoTopBottom
+)
+
+var stereoNames = map[StereoLayout]string{
+	StereoNone:       "none",
+	StereoSideBySide: "sbs",
+	StereoTopBottom:  "tb",
+}
+
+func (l StereoLayout) String() string {
+	if name, ok := stereoNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("StereoLayout(%d)", int(l))
+}
+
+// ParseStereoLayout parses a layout name: "none", "sbs" or "tb".
+func ParseStereoLayout(s string) (StereoLayout, error) {
+	for l, name := range stereoNames {
+		if name == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown stereo layout %q", s)
+}
+
+// Stereo makes the camera a stereo rig: two eyes interocular apart, on
+// either side of the camera along its u axis, each rendering half of the
+// image laid out by layout. The eyes' images line up at convergence, or at
+// the focus distance if zero; the frustums are off-axis rather than toed in,
+// so there is no vertical parallax. An equirectangular projection becomes an
+// omni-directional stereo panorama.
+func Stereo(layout StereoLayout, interocular, convergence float64) CameraOpt {
+	return func(cam *Camera) {
+		cam.stereo = layout
+		cam.interocular = interocular
+		cam.view.Convergence = convergence
+		switch layout {
+		case StereoSideBySide:
+			cam.view.Aspect /= 2
+		case StereoTopBottom:
+			cam.view.Aspect *= 2
+		}
+	}
+}
+
+// eyeView returns the view of the left (0) or right (1) eye.
+func (cam Camera) eyeView(eye int) View {
+	v := cam.view
+	if cam.stereo != StereoNone {
+		v.Eye = (float64(eye) - 0.5) * cam.interocular
+	}
+	return v
+}
+
+// film maps pixel c, offset by (dx, dy) within it, to the eye that sees it
+// and the position (s, t) on that eye's film.
+func (cam Camera) film(c Coords, dx, dy float64) (eye int, s, t float64) {
+	var (
+		i, j = c.I, c.J
+		w, h = cam.width, cam.height
+	)
+	// the left eye gets the extra column or row of an odd sized image
+	switch cam.stereo {
+	case StereoSideBySide:
+		if left := (w + 1) / 2; i >= left {
+			i, w, eye = i-left, w/2, 1
+		} else {
+			w = left
+		}
+	case StereoTopBottom:
+		// rows run bottom to top, so the bottom half is the right eye's
+		if right := h / 2; j >= right {
+			j, h = j-right, (h+1)/2
+		} else {
+			h, eye = right, 1
+		}
+	}
+	return eye, (float64(i) + dx) / (float64(w) - 1), (float64(j) + dy) / (float64(h) - 1)
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestStereoConvergence(t *testing.T) {
+	// both eyes' center rays meet on the axis at the convergence distance
+	for _, eye := range []float64{-0.5, 0.5} {
+		v := testView(60, 1)
+		v.Eye, v.Convergence = eye, 4
+		r, _ := NewPerspective(v).Ray(0.5, 0.5, nil)
+		if !almostEqual(r.Orig.X, eye) {
+			t.Errorf("eye %v: origin x = %v", eye, r.Orig.X)
+		}
+		if p := r.Orig.Add(r.Dir.MulS(-4 / r.Dir.Z)); !vecAlmostEqual(p, vecmath.Point3{X: 0, Y: 0, Z: -4}) {
+			t.Errorf("eye %v: center ray crosses z = -4 at %v, want the axis", eye, p)
+		}
+	}
+}
+
+func TestStereoLayout(t *testing.T) {
+	var (
+		from = vecmath.Point3{}
+		at   = vecmath.Point3{X: 0, Y: 0, Z: -1}
+		up   = vecmath.Vec3{X: 0, Y: 1, Z: 0}
+	)
+	tests := []struct {
+		layout    StereoLayout
+		c         Coords
+		eye       int
+		s, t      float64
+		eyeAspect float64
+	}{
+		{StereoSideBySide, Coords{0, 0}, 0, 0, 0, 1},
+		{StereoSideBySide, Coords{15, 4}, 1, 5.0 / 9, 4.0 / 9, 1},
+		{StereoTopBottom, Coords{9, 13}, 0, 1, 1.0 / 3, 1},
+		{StereoTopBottom, Coords{9, 6}, 1, 1, 2.0 / 3, 1},
+	}
+	for _, tt := range tests {
+		// two square eyes
+		cam := NewCamera(20, 10, 1, 1, 1, from, at, up, 90, 0, 1, Stereo(tt.layout, 0.1, 0))
+		if tt.layout == StereoTopBottom {
+			cam = NewCamera(10, 20, 1, 1, 1, from, at, up, 90, 0, 1, Stereo(tt.layout, 0.1, 0))
+		}
+		eye, s, st := cam.film(tt.c, 0, 0)
+		if eye != tt.eye || !almostEqual(s, tt.s) || !almostEqual(st, tt.t) {
+			t.Errorf("%v: film(%v) = %v, %v, %v, want %v, %v, %v", tt.layout, tt.c, eye, s, st, tt.eye, tt.s, tt.t)
+		}
+		if a := cam.view.Aspect; !almostEqual(a, tt.eyeAspect) {
+			t.Errorf("%v: eye aspect = %v, want %v", tt.layout, a, tt.eyeAspect)
+		}
+		// the left eye sits to the left
+		if l, r := cam.eyeView(0).Eye, cam.eyeView(1).Eye; !almostEqual(l, -0.05) || !almostEqual(r, 0.05) {
+			t.Errorf("%v: eyes at %v and %v", tt.layout, l, r)
+		}
+	}
+
+	// odd sized images give the left eye the extra column or row, and every
+	// pixel still lands on its eye's film
+	for _, tt := range []struct {
+		layout StereoLayout
+		c      Coords
+		eye    int
+		s, t   float64
+	}{
+		{StereoSideBySide, Coords{10, 0}, 0, 1, 0},
+		{StereoSideBySide, Coords{11, 4}, 1, 0, 4.0 / 9},
+		{StereoSideBySide, Coords{20, 9}, 1, 1, 1},
+		{StereoTopBottom, Coords{0, 20}, 0, 0, 1},
+		{StereoTopBottom, Coords{0, 10}, 0, 0, 0},
+		{StereoTopBottom, Coords{9, 9}, 1, 1, 1},
+		{StereoTopBottom, Coords{9, 0}, 1, 1, 0},
+	} {
+		cam := NewCamera(21, 10, 1, 1, 1, from, at, up, 90, 0, 1, Stereo(tt.layout, 0.1, 0))
+		if tt.layout == StereoTopBottom {
+			cam = NewCamera(10, 21, 1, 1, 1, from, at, up, 90, 0, 1, Stereo(tt.layout, 0.1, 0))
+		}
+		if eye, s, st := cam.film(tt.c, 0, 0); eye != tt.eye || !almostEqual(s, tt.s) || !almostEqual(st, tt.t) {
+			t.Errorf("%v odd: film(%v) = %v, %v, %v, want %v, %v, %v", tt.layout, tt.c, eye, s, st, tt.eye, tt.s, tt.t)
+		}
+	}
+
+	for _, name := range []string{"none", "sbs", "tb"} {
+		if l, err := ParseStereoLayout(name); err != nil || l.String() != name {
+			t.Errorf("ParseStereoLayout(%q) = %v, %v", name, l, err)
+		}
+	}
+}
+
+func TestOmniDirectionalStereo(t *testing.T) {
+	v := testView(0, 2)
+	v.Eye = 0.5
+	e := NewEquirectangular(v)
+	// looking forward the right eye is to the +X side, looking right (+X)
+	// it is behind, and at the poles both eyes meet
+	for _, tt := range []struct {
+		s, t float64
+		orig vecmath.Point3
+	}{
+		{0.5, 0.5, vecmath.Point3{X: 0.5, Y: 0, Z: 0}},
+		{0.75, 0.5, vecmath.Point3{X: 0, Y: 0, Z: 0.5}},
+		{0.5, 1, vecmath.Point3{}},
+	} {
+		r, _ := e.Ray(tt.s, tt.t, nil)
+		if !vecAlmostEqual(r.Orig, tt.orig) {
+			t.Errorf("Ray(%v, %v) starts at %v, want %v", tt.s, tt.t, r.Orig, tt.orig)
+		}
+		if d := r.Orig.Dot(r.Dir); math.Abs(d) > 1e-9 {
+			t.Errorf("Ray(%v, %v) offset is not perpendicular to its direction", tt.s, tt.t)
+		}
+	}
+}
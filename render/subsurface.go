@@ -0,0 +1,87 @@
+package render
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// maxWalkSteps bounds the scattering events of a random walk through a
+// medium; longer walks are absorbed. They do not count towards the camera's
+// depth, as dense media scatter far more often than surfaces.
+const maxWalkSteps = 256
+
+// walk random walks r, just refracted into medium m, through the medium's
+// interior until it leaves through the surface. It returns the outgoing ray
+// and the walk's throughput, or false if the path was absorbed. Distances
+// are sampled on one color channel at a time and weighted by the average
+// over channels, so chromatic media stay unbiased. hr is left with the exit
+// point.
+func (cam Camera) walk(r geometry.Ray, m geometry.Medium, lambda float64, world *geometry.Hittables, hr *geometry.HitRecord) (geometry.Ray, vecmath.Color, bool) {
+	var (
+		sigmaS, sigmaA = m.Coefficients()
+		sigmaT         = sigmaS.Add(sigmaA)
+		albedo         = geometry.ScatteringAlbedo(sigmaS, sigmaA)
+		mult           = vecmath.Color{X: 1, Y: 1, Z: 1}
+		bs             geometry.BSDFSample
+	)
+	if lambda != 0 {
+		// the albedo converts like a color, bounded by one
+		sigmaT = cam.spectrum(sigmaT, lambda)
+		albedo = cam.spectrum(albedo, lambda)
+		albedo = vecmath.Color{X: math.Min(albedo.X, 1), Y: math.Min(albedo.Y, 1), Z: math.Min(albedo.Z, 1)}
+	}
+	sigmaS = albedo.Mul(sigmaT)
+
+	for range maxWalkSteps {
+		r.Stats.AddRay()
+		if !world.Hit(r, 1e-3, math.MaxFloat64, hr) {
+			// not a closed surface; let the path carry on
+			return r, mult, true
+		}
+
+		var (
+			length   = r.Dir.Len()
+			boundary = hr.T * length
+			channel  = [3]float64{sigmaT.X, sigmaT.Y, sigmaT.Z}[r.Rand.IntN(3)]
+			dist     = math.Inf(1) // through a transparent channel
+		)
+		if channel > 0 {
+			dist = -math.Log(1-r.Rand.Float64()) / channel
+		}
+		if dist < boundary {
+			// scatter inside: transmittance times scattering over the
+			// average density of picking dist, which is not zero as the
+			// channel it was picked on is not transparent
+			tr := transmittance(sigmaT, dist)
+			mult = mult.Mul(sigmaS.Mul(tr)).MulS(3 / sigmaT.Mul(tr).Sum())
+			r = geometry.Ray{Orig: r.At(dist / length), Dir: m.Phase(r.Dir.Unit(), r.Rand), Stats: r.Stats, Rand: r.Rand, Time: r.Time}
+			continue
+		}
+
+		// reach the surface: transmittance over the average probability of
+		// getting this far
+		tr := transmittance(sigmaT, boundary)
+		mult = mult.MulS(3 / tr.Sum()).Mul(tr)
+
+		hr.Lambda = lambda
+		if !hr.M.Sample(r.Dir.Unit().Neg(), *hr, &bs) {
+			return r, vecmath.Color{}, false
+		}
+		mult = mult.Mul(cam.spectrum(bs.Weight, lambda))
+		r = geometry.Ray{Orig: hr.P, Dir: bs.Wi, Stats: r.Stats, Rand: r.Rand, Time: r.Time}
+		if bs.Wi.Dot(hr.N) < 0 {
+			// refracted out
+			return r, mult, true
+		}
+		// reflected back inside
+	}
+	return r, vecmath.Color{}, false
+}
+
+// transmittance returns the fraction of light left after dist through a
+// medium of extinction sigmaT.
+func transmittance(sigmaT vecmath.Color, dist float64) vecmath.Color {
+	return vecmath.Color{X: math.Exp(-sigmaT.X * dist), Y: math.Exp(-sigmaT.Y * dist), Z: math.Exp(-sigmaT.Z * dist)}
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestWalk(t *testing.T) {
+	walk := func(m material.Subsurface, n int) (mean vecmath.Color, exits int) {
+		var (
+			cam    = NewCamera(1, 1, 1, 1, 1, vecmath.Point3{}, vecmath.Point3{X: 0, Y: 0, Z: -1}, vecmath.Vec3{X: 0, Y: 1, Z: 0}, 90, 0, 1)
+			sphere = geometry.Sphere{Center: vecmath.Point3{}, R: 1, M: m}
+			world  = geometry.NewHittables(sphere)
+			hr     geometry.HitRecord
+		)
+		for range n {
+			// just inside the top, heading down
+			r, w, ok := cam.walk(geometry.Ray{Orig: vecmath.Point3{X: 0, Y: 0.999, Z: 0}, Dir: vecmath.Vec3{X: 0, Y: -1, Z: 0}}, m, 0, &world, &hr)
+			if !ok {
+				continue
+			}
+			exits++
+			mean = mean.Add(w)
+			if r.Orig.Len() < 0.999 || r.Dir.Dot(r.Orig) <= 0 {
+				t.Fatalf("walk left from %v along %v, not out of the sphere", r.Orig, r.Dir)
+			}
+		}
+		return mean.DivS(float64(n)), exits
+	}
+
+	// without absorption or a refracting boundary every path gets out, with
+	// all its energy
+	const n = 20000
+	gray, exits := walk(material.NewSubsurface(vecmath.Color{X: 2, Y: 2, Z: 2}, vecmath.Color{}, material.SubsurfaceIOR(1)), n)
+	if exits != n || !vecAlmostEqual(gray, vecmath.Color{X: 1, Y: 1, Z: 1}) {
+		t.Errorf("%d of %d paths got out of a non-absorbing medium, with throughput %v", exits, n, gray)
+	}
+
+	// and however chromatic the medium, each channel keeps its energy on
+	// average
+	clear, _ := walk(material.NewSubsurface(vecmath.Color{X: 1, Y: 2, Z: 3}, vecmath.Color{}, material.SubsurfaceIOR(1)), n)
+	for _, c := range []float64{clear.X, clear.Y, clear.Z} {
+		if math.Abs(c-1) > 0.2 {
+			t.Errorf("chromatic non-absorbing walk throughput %v, want 1 per channel", clear)
+			break
+		}
+	}
+
+	// a channel the medium is transparent in passes straight through, and
+	// keeps its energy too
+	transparent, exits := walk(material.NewSubsurface(vecmath.Color{X: 2, Y: 2, Z: 0}, vecmath.Color{}, material.SubsurfaceIOR(1)), n)
+	if exits != n || math.IsNaN(transparent.Sum()) || math.Abs(transparent.Z-1) > 0.2 || math.Abs(transparent.X-1) > 0.2 {
+		t.Errorf("%d of %d paths got out of a medium transparent in blue, with throughput %v", exits, n, transparent)
+	}
+
+	// with the same absorption, channels that scatter more keep more light
+	absorbing, _ := walk(material.NewSubsurface(vecmath.Color{X: 1, Y: 3, Z: 6}, vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5}, material.SubsurfaceIOR(1)), n)
+	if !(absorbing.X < absorbing.Y && absorbing.Y < absorbing.Z && absorbing.Z < 1) {
+		t.Errorf("absorbing walk throughput %v", absorbing)
+	}
+}
@@ -0,0 +1,435 @@
+package scene
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mhv2109/RayTracing/anim"
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// DefaultCamera is the view of the Random scene, and of scene files that
+// leave parts of the camera out.
+var DefaultCamera = anim.CameraParams{
+	LookFrom:  vecmath.Point3{X: 13, Y: 2, Z: 3},
+	LookAt:    vecmath.Point3{X: 0, Y: 0, Z: 0},
+	VUp:       vecmath.Vec3{X: 0, Y: 1, Z: 0},
+	VFov:      20,
+	Aperture:  0.1,
+	FocusDist: 10,
+}
+
+// File is a scene read from a scene file by Load.
+type File struct {
+	Camera anim.CameraParams
+
+	// keyframes Camera, see anim.CameraAnimation.At
+	CameraAnimation anim.CameraAnimation
+
+	// the camera projection by name, see render.ParseProjection, or empty if
+	// the file does not choose one
+	Projection string
+
+	// the scene's objects, wrapped in geometry.Named where they are named.
+	// Animated objects are where they are written; see WorldDuring
+	World *geometry.Hittables
+
+	// keyframes the objects of World with keys, by index
+	moves map[int]anim.TransformAnimation
+}
+
+// WorldDuring returns World with its animated objects moving as their keys
+// place them while the shutter is open over frames [open, close], see
+// geometry.NewMoving.
+func (f *File) WorldDuring(open, close float64) *geometry.Hittables {
+	if len(f.moves) == 0 {
+		return f.World
+	}
+	world := geometry.NewHittables()
+	for k, obj := range f.World.Objects {
+		if move, ok := f.moves[k]; ok {
+			obj = place(obj, move.At, open, close)
+		}
+		world.Add(obj)
+	}
+	return &world
+}
+
+// place moves obj by at, inside its name if it has one so that it can still
+// be found by name.
+func place(obj geometry.Hittable, at func(float64) geometry.Transform, open, close float64) geometry.Hittable {
+	if n, ok := obj.(geometry.Named); ok {
+		n.Hittable = geometry.NewMoving(n.Hittable, at, open, close)
+		return n
+	}
+	return geometry.NewMoving(obj, at, open, close)
+}
+
+// fileJSON is the layout of a scene file, see Load.
+type fileJSON struct {
+	Camera    cameraJSON                 `json:"camera"`
+	MTLLib    []string                   `json:"mtllib"`
+	Materials map[string]json.RawMessage `json:"materials"`
+	Objects   []objectJSON               `json:"objects"`
+}
+
+type cameraJSON struct {
+	LookFrom  vec3    `json:"lookFrom"`
+	LookAt    vec3    `json:"lookAt"`
+	VUp       vec3    `json:"vUp"`
+	VFov      float64 `json:"vFov"`
+	Aperture  float64 `json:"aperture"`
+	FocusDist float64 `json:"focusDist"`
+
+	Projection string          `json:"projection"`
+	Keys       []cameraKeyJSON `json:"keys"`
+}
+
+// cameraKeyJSON is a camera keyframe. Parameters left out are not keyed at
+// this frame.
+type cameraKeyJSON struct {
+	Frame     float64  `json:"frame"`
+	Interp    string   `json:"interp"`
+	LookFrom  *vec3    `json:"lookFrom"`
+	LookAt    *vec3    `json:"lookAt"`
+	VFov      *float64 `json:"vFov"`
+	Aperture  *float64 `json:"aperture"`
+	FocusDist *float64 `json:"focusDist"`
+}
+
+type principledJSON struct {
+	BaseColor          vec3    `json:"baseColor"`
+	Metallic           float64 `json:"metallic"`
+	Roughness          float64 `json:"roughness"`
+	Specular           float64 `json:"specular"`
+	Sheen              float64 `json:"sheen"`
+	SheenTint          float64 `json:"sheenTint"`
+	Clearcoat          float64 `json:"clearcoat"`
+	ClearcoatRoughness float64 `json:"clearcoatRoughness"`
+	Transmission       float64 `json:"transmission"`
+	IOR                float64 `json:"ior"`
+}
+
+type objectJSON struct {
+	Name     string      `json:"name"`
+	Material string      `json:"material"`
+	Sphere   *sphereJSON `json:"sphere"`
+	Quad     *quadJSON   `json:"quad"`
+
+	Keys []transformKeyJSON `json:"keys"`
+}
+
+// transformKeyJSON is an object keyframe, see geometry.Transform. Parts left
+// out are not keyed at this frame.
+type transformKeyJSON struct {
+	Frame     float64 `json:"frame"`
+	Interp    string  `json:"interp"`
+	Translate *vec3   `json:"translate"`
+	Rotate    *vec3   `json:"rotate"`
+	Scale     *vec3   `json:"scale"`
+}
+
+type sphereJSON struct {
+	Center vec3    `json:"center"`
+	Radius float64 `json:"radius"`
+}
+
+// quadJSON is a parallelogram with a corner at q and edges u and v, see
+// geometry.Quad.
+type quadJSON struct {
+	Q vec3 `json:"q"`
+	U vec3 `json:"u"`
+	V vec3 `json:"v"`
+}
+
+// vec3 is a vector written as a JSON array, [x, y, z].
+type vec3 [3]float64
+
+func toVec3(v vecmath.Vec3) vec3 {
+	return vec3{v.X, v.Y, v.Z}
+}
+
+func (v vec3) vec() vecmath.Vec3 {
+	return vecmath.Vec3{X: v[0], Y: v[1], Z: v[2]}
+}
+
+// Load reads the scene file at path. Scene files are JSON, for example:
+//
+//	{
+//		"camera": {
+//			"lookFrom": [0, 1, 5], "lookAt": [0, 1, 0], "vFov": 40,
+//			"projection": "perspective",
+//			"keys": [
+//				{"frame": 1, "lookFrom": [0, 1, 5], "interp": "bezier"},
+//				{"frame": 60, "lookFrom": [4, 2, 3], "vFov": 30}
+//			]
+//		},
+//		"mtllib": ["materials.mtl"],
+//		"materials": {
+//			"floor": {"baseColor": [0.5, 0.5, 0.5], "roughness": 0.9},
+//			"gold": {"baseColor": [1, 0.8, 0.3], "metallic": 1, "roughness": 0.2}
+//		},
+//		"objects": [
+//			{"name": "floor", "quad": {"q": [-5, 0, 5], "u": [10, 0, 0], "v": [0, 0, -10]}, "material": "floor"},
+//			{"name": "ball", "sphere": {"center": [0, 1, 0], "radius": 1}, "material": "gold",
+//				"keys": [
+//					{"frame": 1, "translate": [0, 0, 0]},
+//					{"frame": 60, "translate": [2, 0, 0], "interp": "bezier"}
+//				]}
+//		]
+//	}
+//
+// Camera parameters left out take their DefaultCamera values. Camera keys
+// animate the parameters they set, lookFrom, lookAt, vFov, aperture and
+// focusDist, with linear (the default) or bezier interpolation from each key
+// to the next; other parameters keep their camera values. Object keys
+// likewise animate a geometry.Transform of the object as written, with
+// rotations and scales about the origin. Materials are
+// material.Principled, with the parameters of material.PrincipledParams, and
+// also come from the MTL libraries listed in mtllib, read by
+// material.ReadMTL; materials defined in the file replace MTL materials of the
+// same name. Paths are relative to the scene file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// parse decodes a scene file, resolving relative paths against dir.
+func parse(data []byte, dir string) (*File, error) {
+	fj := fileJSON{
+		Camera: cameraJSON{
+			LookFrom:  toVec3(DefaultCamera.LookFrom),
+			LookAt:    toVec3(DefaultCamera.LookAt),
+			VUp:       toVec3(DefaultCamera.VUp),
+			VFov:      DefaultCamera.VFov,
+			Aperture:  DefaultCamera.Aperture,
+			FocusDist: DefaultCamera.FocusDist,
+		},
+	}
+	if err := decodeStrict(data, &fj); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]material.PrincipledParams)
+	for _, lib := range fj.MTLLib {
+		mtl, err := readMTL(resolve(dir, lib))
+		if err != nil {
+			return nil, err
+		}
+		for name, pp := range mtl {
+			params[name] = pp
+		}
+	}
+	for name, raw := range fj.Materials {
+		d := material.DefaultPrincipledParams(vecmath.Color{X: 0.8, Y: 0.8, Z: 0.8})
+		mj := principledJSON{
+			BaseColor:          toVec3(d.BaseColor),
+			Roughness:          d.Roughness,
+			Specular:           d.Specular,
+			ClearcoatRoughness: d.ClearcoatRoughness,
+			IOR:                d.IOR,
+		}
+		if err := decodeStrict(raw, &mj); err != nil {
+			return nil, fmt.Errorf("material %q: %w", name, err)
+		}
+		params[name] = material.PrincipledParams{
+			BaseColor:          mj.BaseColor.vec(),
+			Metallic:           mj.Metallic,
+			Roughness:          mj.Roughness,
+			Specular:           mj.Specular,
+			Sheen:              mj.Sheen,
+			SheenTint:          mj.SheenTint,
+			Clearcoat:          mj.Clearcoat,
+			ClearcoatRoughness: mj.ClearcoatRoughness,
+			Transmission:       mj.Transmission,
+			IOR:                mj.IOR,
+		}
+	}
+	materials := make(map[string]material.Principled, len(params))
+	for name, pp := range params {
+		materials[name] = pp.Principled()
+	}
+
+	var (
+		world = geometry.NewHittables()
+		moves = make(map[int]anim.TransformAnimation)
+	)
+	for k, o := range fj.Objects {
+		obj, err := o.hittable(materials)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", k, err)
+		}
+		world.Add(obj)
+
+		if len(o.Keys) > 0 {
+			if moves[k], err = o.animation(); err != nil {
+				return nil, fmt.Errorf("object %d: %w", k, err)
+			}
+		}
+	}
+
+	c := fj.Camera
+	move, err := c.animation()
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		Camera: anim.CameraParams{
+			LookFrom:  c.LookFrom.vec(),
+			LookAt:    c.LookAt.vec(),
+			VUp:       c.VUp.vec(),
+			VFov:      c.VFov,
+			Aperture:  c.Aperture,
+			FocusDist: c.FocusDist,
+		},
+		CameraAnimation: move,
+		Projection:      c.Projection,
+		World:           &world,
+		moves:           moves,
+	}, nil
+}
+
+// animation builds a track for each camera parameter from the keys that set
+// it.
+func (c cameraJSON) animation() (anim.CameraAnimation, error) {
+	var (
+		lookFrom, lookAt          []anim.Key[vecmath.Vec3]
+		vfov, aperture, focusDist []anim.Key[anim.Scalar]
+	)
+	scalar := func(keys []anim.Key[anim.Scalar], frame float64, v *float64, interp anim.Interpolation) []anim.Key[anim.Scalar] {
+		if v == nil {
+			return keys
+		}
+		return append(keys, anim.Key[anim.Scalar]{Frame: frame, Value: anim.Scalar(*v), Interp: interp})
+	}
+
+	for _, k := range c.Keys {
+		interp, err := interpolation(k.Interp)
+		if err != nil {
+			return anim.CameraAnimation{}, fmt.Errorf("camera key at frame %v: %w", k.Frame, err)
+		}
+		lookFrom = vector(lookFrom, k.Frame, k.LookFrom, interp)
+		lookAt = vector(lookAt, k.Frame, k.LookAt, interp)
+		vfov = scalar(vfov, k.Frame, k.VFov, interp)
+		aperture = scalar(aperture, k.Frame, k.Aperture, interp)
+		focusDist = scalar(focusDist, k.Frame, k.FocusDist, interp)
+	}
+	return anim.CameraAnimation{
+		LookFrom:  anim.NewTrack(lookFrom...),
+		LookAt:    anim.NewTrack(lookAt...),
+		VFov:      anim.NewTrack(vfov...),
+		Aperture:  anim.NewTrack(aperture...),
+		FocusDist: anim.NewTrack(focusDist...),
+	}, nil
+}
+
+// animation builds a track for each part of the object's transform from the
+// keys that set it.
+func (o objectJSON) animation() (anim.TransformAnimation, error) {
+	var translate, rotate, scale []anim.Key[vecmath.Vec3]
+	for _, k := range o.Keys {
+		interp, err := interpolation(k.Interp)
+		if err != nil {
+			return anim.TransformAnimation{}, fmt.Errorf("key at frame %v: %w", k.Frame, err)
+		}
+		if s := k.Scale; s != nil && (s[0] == 0 || s[1] == 0 || s[2] == 0) {
+			return anim.TransformAnimation{}, fmt.Errorf("key at frame %v: scale %v has a zero component", k.Frame, *s)
+		}
+		translate = vector(translate, k.Frame, k.Translate, interp)
+		rotate = vector(rotate, k.Frame, k.Rotate, interp)
+		scale = vector(scale, k.Frame, k.Scale, interp)
+	}
+	return anim.TransformAnimation{
+		Translate: anim.NewTrack(translate...),
+		Rotate:    anim.NewTrack(rotate...),
+		Scale:     anim.NewTrack(scale...),
+	}, nil
+}
+
+// vector appends a key setting v at frame to keys, unless v is not set.
+func vector(keys []anim.Key[vecmath.Vec3], frame float64, v *vec3, interp anim.Interpolation) []anim.Key[vecmath.Vec3] {
+	if v == nil {
+		return keys
+	}
+	return append(keys, anim.Key[vecmath.Vec3]{Frame: frame, Value: v.vec(), Interp: interp})
+}
+
+// interpolation parses a key's interpolation, linear if left out.
+func interpolation(s string) (anim.Interpolation, error) {
+	if s == "" {
+		return anim.Linear, nil
+	}
+	return anim.ParseInterpolation(s)
+}
+
+// hittable returns the shape o describes, with its material.
+func (o objectJSON) hittable(materials map[string]material.Principled) (geometry.Hittable, error) {
+	m, ok := materials[o.Material]
+	if !ok {
+		return nil, fmt.Errorf("no material named %q", o.Material)
+	}
+
+	var obj geometry.Hittable
+	switch {
+	case o.Sphere != nil && o.Quad != nil:
+		return nil, errors.New("an object is either a sphere or a quad, not both")
+	case o.Sphere != nil:
+		if o.Sphere.Radius <= 0 {
+			return nil, fmt.Errorf("sphere radius %v is not positive", o.Sphere.Radius)
+		}
+		obj = geometry.Sphere{Center: o.Sphere.Center.vec(), R: o.Sphere.Radius, M: m}
+	case o.Quad != nil:
+		obj = geometry.Quad{Q: o.Quad.Q.vec(), U: o.Quad.U.vec(), V: o.Quad.V.vec(), M: m}
+	default:
+		return nil, errors.New("no shape: want a sphere or a quad")
+	}
+
+	if o.Name != "" {
+		obj = geometry.Named{Hittable: obj, Name: o.Name}
+	}
+	return obj, nil
+}
+
+// decodeStrict decodes JSON into v, rejecting unknown fields so that typos
+// are not silently ignored.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func readMTL(path string) (map[string]material.PrincipledParams, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	mtl, err := material.ReadMTL(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mtl, nil
+}
+
+// resolve returns path relative to dir, unless it is absolute.
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,201 @@
+package scene
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/anim"
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "lib.mtl"), `
+newmtl gold
+Kd 1 0.8 0.3
+Pm 1
+Pr 0.2
+
+newmtl red
+Kd 1 0 0
+`)
+	path := filepath.Join(dir, "scene.json")
+	writeFile(t, path, `{
+		"camera": {"lookFrom": [0, 1, 5], "lookAt": [0, 1, 0], "vFov": 40, "projection": "fisheye"},
+		"mtllib": ["lib.mtl"],
+		"materials": {
+			"red": {"baseColor": [0.5, 0, 0], "roughness": 0.3}
+		},
+		"objects": [
+			{"name": "floor", "quad": {"q": [-5, 0, 5], "u": [10, 0, 0], "v": [0, 0, -10]}, "material": "red"},
+			{"name": "ball", "sphere": {"center": [0, 1, 0], "radius": 1}, "material": "gold"},
+			{"sphere": {"center": [2, 0.5, 0], "radius": 0.5}, "material": "gold"}
+		]
+	}`)
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DefaultCamera
+	want.LookFrom = vecmath.Point3{X: 0, Y: 1, Z: 5}
+	want.LookAt = vecmath.Point3{X: 0, Y: 1, Z: 0}
+	want.VFov = 40
+	if f.Camera != want {
+		t.Fatalf("camera = %#v, want %#v", f.Camera, want)
+	}
+
+	if f.Projection != "fisheye" {
+		t.Fatalf("projection = %q, want fisheye", f.Projection)
+	}
+
+	if n := len(f.World.Objects); n != 3 {
+		t.Fatalf("loaded %d objects, want 3", n)
+	}
+
+	gold := material.DefaultPrincipledParams(vecmath.Color{X: 1, Y: 0.8, Z: 0.3})
+	gold.Metallic, gold.Roughness = 1, 0.2
+	ball, ok := geometry.FindNamed(f.World.Objects, "ball")
+	if !ok {
+		t.Fatalf("no object named ball")
+	}
+	if s := ball.(geometry.Named).Hittable.(geometry.Sphere); s.M != gold.Principled() {
+		t.Fatalf("ball material = %#v, want the MTL gold", s.M)
+	}
+
+	// the file's red replaces the MTL library's
+	red := material.DefaultPrincipledParams(vecmath.Color{X: 0.5, Y: 0, Z: 0})
+	red.Roughness = 0.3
+	floor, ok := geometry.FindNamed(f.World.Objects, "floor")
+	if !ok {
+		t.Fatalf("no object named floor")
+	}
+	if q := floor.(geometry.Named).Hittable.(geometry.Quad); q.M != red.Principled() {
+		t.Fatalf("floor material = %#v, want the file's red", q.M)
+	}
+}
+
+func TestLoadCameraKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scene.json")
+	writeFile(t, path, `{
+		"camera": {
+			"lookFrom": [0, 1, 5], "vFov": 40,
+			"keys": [
+				{"frame": 1, "lookFrom": [0, 1, 5], "interp": "bezier"},
+				{"frame": 10, "vFov": 20},
+				{"frame": 21, "lookFrom": [10, 1, 5], "vFov": 30}
+			]
+		}
+	}`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(frame float64) anim.CameraParams {
+		return f.CameraAnimation.At(frame, f.Camera)
+	}
+	if got, want := at(1).LookFrom, (vecmath.Point3{X: 0, Y: 1, Z: 5}); got != want {
+		t.Fatalf("frame 1 lookFrom = %#v, want %#v", got, want)
+	}
+	if got, want := at(21).LookFrom, (vecmath.Point3{X: 10, Y: 1, Z: 5}); got != want {
+		t.Fatalf("frame 21 lookFrom = %#v, want %#v", got, want)
+	}
+	if got := at(11).LookFrom.X; got <= 0 || got >= 10 {
+		t.Fatalf("frame 11 lookFrom.X = %v, want between the keys", got)
+	}
+
+	// vFov is keyed at 10 and 21 only, and holds its first key before that
+	if got := at(1).VFov; got != 20 {
+		t.Fatalf("frame 1 vFov = %v, want 20", got)
+	}
+	if got := at(15.5).VFov; !almostEqual(got, 25) {
+		t.Fatalf("frame 15.5 vFov = %v, want 25", got)
+	}
+
+	// unkeyed parameters keep the camera's values
+	if got := at(15); got.LookAt != f.Camera.LookAt || got.FocusDist != f.Camera.FocusDist {
+		t.Fatalf("frame 15 = %#v, want lookAt and focusDist of %#v", got, f.Camera)
+	}
+}
+
+func TestLoadObjectKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scene.json")
+	writeFile(t, path, `{
+		"materials": {"m": {}},
+		"objects": [
+			{"name": "ball", "sphere": {"center": [0, 0, 0], "radius": 1}, "material": "m",
+				"keys": [
+					{"frame": 1, "translate": [0, 0, 0]},
+					{"frame": 11, "translate": [10, 0, 0]}
+				]},
+			{"sphere": {"center": [0, -100, 0], "radius": 99}, "material": "m"}
+		]
+	}`)
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	center := func(frame float64) vecmath.Point3 {
+		obj, ok := geometry.FindNamed(f.WorldDuring(frame, frame).Objects, "ball")
+		if !ok {
+			t.Fatalf("frame %v: ball not found", frame)
+		}
+		b := obj.BoundingBox()
+		return b.Min.Add(b.Max).MulS(0.5)
+	}
+	for frame, x := range map[float64]float64{1: 0, 6: 5, 11: 10} {
+		if got := center(frame); !almostEqual(got.X, x) || !almostEqual(got.Y, 0) {
+			t.Fatalf("frame %v center = %#v, want x = %v", frame, got, x)
+		}
+	}
+
+	// while the shutter is open, the ball moves over every place between
+	if obj, _ := geometry.FindNamed(f.WorldDuring(1, 11).Objects, "ball"); !almostEqual(obj.BoundingBox().Min.X, -1) || !almostEqual(obj.BoundingBox().Max.X, 11) {
+		t.Fatalf("box while moving = %#v, want x from -1 to 11", obj.BoundingBox())
+	}
+
+	// unkeyed objects stay where they are written
+	if got, want := f.WorldDuring(6, 7).Objects[1], f.World.Objects[1]; got != want {
+		t.Fatalf("unkeyed object = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"syntax":           `{"objects": [}`,
+		"unknown field":    `{"camera": {"lookfrm": [0, 0, 0]}}`,
+		"unknown material": `{"objects": [{"sphere": {"center": [0, 0, 0], "radius": 1}, "material": "none"}]}`,
+		"no shape":         `{"materials": {"m": {}}, "objects": [{"material": "m"}]}`,
+		"bad radius":       `{"materials": {"m": {}}, "objects": [{"sphere": {"center": [0, 0, 0], "radius": 0}, "material": "m"}]}`,
+		"missing mtllib":   `{"mtllib": ["missing.mtl"]}`,
+		"interpolation":    `{"camera": {"keys": [{"frame": 1, "vFov": 30, "interp": "cubic"}]}}`,
+		"zero scale":       `{"materials": {"m": {}}, "objects": [{"sphere": {"center": [0, 0, 0], "radius": 1}, "material": "m", "keys": [{"frame": 1, "scale": [1, 0, 1]}]}]}`,
+	} {
+		path := filepath.Join(dir, "scene.json")
+		writeFile(t, path, content)
+		if _, err := Load(path); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package scene builds the built-in demo scenes and loads scene files.
+package scene
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/mhv2109/RayTracing/accel"
+	"github.com/mhv2109/RayTracing/anim"
+	"github.com/mhv2109/RayTracing/geometry"
+	"github.com/mhv2109/RayTracing/material"
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Random builds the objects of the book's final scene. The small spheres
+// are placed from seed, so the same seed always yields the same scene. The
+// ground and the big glass, matte and metal spheres are Named after what
+// they are.
+func Random(seed int64, dt material.DiffusionType) *geometry.Hittables {
+	var (
+		world = geometry.NewHittables()
+		rng   = rand.New(rand.NewSource(seed))
+	)
+
+	// diffusionMaterial allows us to select the diffusion function at runtime
+	diffusionMaterial := func() material.DiffusionOpt {
+		return material.WithDiffusionType(dt)
+	}
+
+	randomVec3 := func(min, max float64) vecmath.Vec3 {
+		scale := max - min
+		return vecmath.Vec3{X: min + rng.Float64()*scale, Y: min + rng.Float64()*scale, Z: min + rng.Float64()*scale}
+	}
+
+	// earth/ground/floor
+
+	ground := geometry.Sphere{
+		Center: vecmath.Point3{X: 0, Y: -1000, Z: 0},
+		R:      1000,
+		M:      material.NewDiffusion(vecmath.Color{X: 0.5, Y: 0.5, Z: 0.5}, diffusionMaterial()),
+	}
+	world.Add(geometry.Named{Hittable: ground, Name: "ground"})
+
+	// big spheres in the center
+
+	sphere1 := geometry.Sphere{
+		Center: vecmath.Point3{X: 0, Y: 1, Z: 0},
+		R:      1,
+		M:      material.NewDielectric(vecmath.Color{X: 1, Y: 1, Z: 1}, material.IndexOfRefraction(1.5)),
+	}
+	world.Add(geometry.Named{Hittable: sphere1, Name: "glass"})
+
+	sphere2 := geometry.Sphere{
+		Center: vecmath.Point3{X: -4, Y: 1, Z: 0},
+		R:      1,
+		M:      material.NewDiffusion(vecmath.Color{X: 0.4, Y: 0.2, Z: 0.1}, diffusionMaterial()),
+	}
+	world.Add(geometry.Named{Hittable: sphere2, Name: "matte"})
+
+	sphere3 := geometry.Sphere{
+		Center: vecmath.Point3{X: 4, Y: 1, Z: 0},
+		R:      1,
+		M:      material.NewMetal(vecmath.Color{X: 0.7, Y: 0.6, Z: 0.5}, material.Fuzz(0)),
+	}
+	world.Add(geometry.Named{Hittable: sphere3, Name: "metal"})
+
+	// add random little spheres all over the ground
+
+	for a := -11; a < 11; a++ {
+		for b := -11; b < 11; b++ {
+			center := vecmath.Point3{X: float64(a) + 0.8*rng.Float64(), Y: 0.2, Z: float64(b) + 0.8*rng.Float64()}
+			if (center.Sub(sphere1.Center).Len() > 1.2) &&
+				(center.Sub(sphere2.Center).Len() > 1.2) &&
+				(center.Sub(sphere3.Center).Len() > 1.2) {
+
+				var (
+					c vecmath.Color
+					m geometry.Material
+				)
+
+				switch choose := rng.Float64(); {
+				case choose < 0.8:
+					// diffuse
+					c = randomVec3(0, 1).Mul(randomVec3(0, 1))
+					m = material.NewDiffusion(c, diffusionMaterial())
+				case choose < 0.95:
+					// metal
+					c = randomVec3(0.5, 1)
+					fuzz := rng.Float64() * 0.5
+					m = material.NewMetal(c, material.Fuzz(fuzz))
+				default:
+					// glass
+					c = vecmath.Color{X: 1, Y: 1, Z: 1}
+					m = material.NewDielectric(c, material.IndexOfRefraction(1.5))
+				}
+
+				world.Add(geometry.Sphere{Center: center, R: 0.2, M: m})
+			}
+		}
+	}
+
+	return &world
+}
+
+// Accelerate builds a BVH from world's objects for O(log n) intersection
+// testing. Objects are tagged first so the id AOV can tell them apart.
+func Accelerate(world *geometry.Hittables) *geometry.Hittables {
+	bvh := accel.NewBVH(geometry.Identify(world.Objects))
+	result := geometry.NewHittables(bvh)
+	return &result
+}
+
+// Turntable spins world once about the Y axis every n frames, moving it while
+// the shutter is open over frames [open, close], see geometry.NewMoving.
+func Turntable(world *geometry.Hittables, n int, open, close float64) *geometry.Hittables {
+	spin := anim.TransformAnimation{
+		Rotate: anim.NewTrack(
+			anim.Key[vecmath.Vec3]{Frame: 0},
+			anim.Key[vecmath.Vec3]{Frame: float64(n), Value: vecmath.Vec3{X: 0, Y: 360, Z: 0}}),
+	}
+	at := func(time float64) geometry.Transform {
+		return spin.At(math.Mod(time, float64(n)))
+	}
+	spun := geometry.NewHittables(geometry.NewMoving(world, at, open, close))
+	return &spun
+}
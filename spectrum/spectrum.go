@@ -0,0 +1,202 @@
+// Package spectrum converts between RGB and spectral quantities and models
+// wavelength-dependent indices of refraction.
+package spectrum
+
+import (
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+// Visible wavelength range, in nm, sampled in spectral mode.
+const (
+	LambdaMin = 380.0
+	LambdaMax = 780.0
+)
+
+// SampleWavelength draws a wavelength uniformly from [LambdaMin, LambdaMax),
+// from rng.
+func SampleWavelength(rng *vecmath.Rand) float64 {
+	return LambdaMin + rng.Float64()*(LambdaMax-LambdaMin)
+}
+
+// lobe is an asymmetric Gaussian used by the CIE fit.
+func lobe(x, mu, s1, s2 float64) float64 {
+	s := s2
+	if x < mu {
+		s = s1
+	}
+	t := (x - mu) / s
+	return math.Exp(-0.5 * t * t)
+}
+
+// cieXYZ returns the CIE 1931 2-degree color matching functions at lambda,
+// using the multi-lobe fit from Wyman et al., "Simple Analytic Approximations
+// to the CIE XYZ Color Matching Functions" (JCGT 2013).
+func cieXYZ(lambda float64) vecmath.Vec3 {
+	return vecmath.Vec3{
+		X: 1.056*lobe(lambda, 599.8, 37.9, 31.0) + 0.362*lobe(lambda, 442.0, 16.0, 26.7) - 0.065*lobe(lambda, 501.1, 20.4, 26.2),
+		Y: 0.821*lobe(lambda, 568.8, 46.9, 40.5) + 0.286*lobe(lambda, 530.9, 16.3, 31.1),
+		Z: 1.217*lobe(lambda, 437.0, 11.8, 36.0) + 0.681*lobe(lambda, 459.0, 26.0, 13.8),
+	}
+}
+
+// xyzToRGB converts CIE XYZ to linear sRGB.
+func xyzToRGB(c vecmath.Vec3) vecmath.Color {
+	return vecmath.Color{
+		X: 3.2404542*c.X - 1.5371385*c.Y - 0.4985314*c.Z,
+		Y: -0.9692660*c.X + 1.8760108*c.Y + 0.0415560*c.Z,
+		Z: 0.0556434*c.X - 0.2040259*c.Y + 1.0572252*c.Z,
+	}
+}
+
+// rgbBasis returns three smooth spectra, peaking in the red, green and blue,
+// that sum to one at every wavelength.
+func rgbBasis(lambda float64) vecmath.Vec3 {
+	b := vecmath.Vec3{
+		X: lobe(lambda, 610, 30, 60),
+		Y: lobe(lambda, 545, 35, 35),
+		Z: lobe(lambda, 450, 60, 30),
+	}
+	return b.MulS(1 / b.Sum())
+}
+
+var (
+	// filmWhite is the linear RGB response of the film to a constant unit
+	// spectrum, used to white balance SpectrumToRGB.
+	filmWhite vecmath.Color
+
+	// basisInv maps an RGB color onto rgbBasis weights such that the
+	// resulting spectrum converts back to the same RGB color.
+	basisInv vecmath.Mat3
+)
+
+func init() {
+	var m vecmath.Mat3
+	for lambda := LambdaMin + 0.5; lambda < LambdaMax; lambda++ {
+		var (
+			rgb = xyzToRGB(cieXYZ(lambda))
+			b   = rgbBasis(lambda)
+		)
+		filmWhite = filmWhite.Add(rgb)
+		for i, ci := range [3]float64{rgb.X, rgb.Y, rgb.Z} {
+			for j, bj := range [3]float64{b.X, b.Y, b.Z} {
+				m[i][j] += ci * bj
+			}
+		}
+	}
+	for i, w := range [3]float64{filmWhite.X, filmWhite.Y, filmWhite.Z} {
+		for j := range m[i] {
+			m[i][j] /= w
+		}
+	}
+	basisInv = m.Inverse()
+}
+
+// RGBToSpectrum returns the value at lambda of a smooth spectrum that
+// reproduces the linear RGB color c through SpectrumToRGB. Saturated colors
+// may need negative spectral values, which are clamped to zero.
+func RGBToSpectrum(c vecmath.Color, lambda float64) float64 {
+	var (
+		b = rgbBasis(lambda)
+		v float64
+	)
+	for j, bj := range [3]float64{b.X, b.Y, b.Z} {
+		w := basisInv[j][0]*c.X + basisInv[j][1]*c.Y + basisInv[j][2]*c.Z
+		v += w * bj
+	}
+	return math.Max(0, v)
+}
+
+// SpectrumToRGB converts the spectral radiance v carried by a path of
+// wavelength lambda into a linear RGB film contribution. Averaged over
+// wavelengths drawn by SampleWavelength, a constant unit spectrum maps to
+// white.
+func SpectrumToRGB(v, lambda float64) vecmath.Color {
+	scale := v * (LambdaMax - LambdaMin)
+	return xyzToRGB(cieXYZ(lambda)).MulS(scale).Div(filmWhite)
+}
+
+// IntegrateRGB returns the linear RGB color of the spectrum f, evaluated at n
+// evenly spaced wavelengths, for spectra that a single RGB triple does not
+// sample well, such as interference colors.
+func IntegrateRGB(f func(lambda float64) float64, n int) vecmath.Color {
+	var (
+		c    vecmath.Color
+		step = (LambdaMax - LambdaMin) / float64(n)
+	)
+	for i := range n {
+		lambda := LambdaMin + (float64(i)+0.5)*step
+		c = c.Add(SpectrumToRGB(f(lambda), lambda))
+	}
+	return c.DivS(float64(n))
+}
+
+// IORModel describes a wavelength-dependent index of refraction.
+type IORModel interface {
+	// IOR returns the index of refraction at lambda, in nm.
+	IOR(lambda float64) float64
+}
+
+// Cauchy is the two-term Cauchy equation n = A + B / lambda^2, with B in
+// square micrometres.
+type Cauchy struct {
+	A, B float64
+}
+
+func (c Cauchy) IOR(lambda float64) float64 {
+	um := lambda / 1000
+	return c.A + c.B/(um*um)
+}
+
+// Sellmeier is the three-term Sellmeier equation, with C in square
+// micrometres.
+type Sellmeier struct {
+	B, C [3]float64
+}
+
+func (s Sellmeier) IOR(lambda float64) float64 {
+	var (
+		um2 = (lambda / 1000) * (lambda / 1000)
+		n2  = 1.0
+	)
+	for i := range s.B {
+		n2 += s.B[i] * um2 / (um2 - s.C[i])
+	}
+	return math.Sqrt(n2)
+}
+
+// Dispersive glass presets.
+var (
+	BK7     = Sellmeier{B: [3]float64{1.03961212, 0.231792344, 1.01046945}, C: [3]float64{0.00600069867, 0.0200179144, 103.560653}}
+	Diamond = Sellmeier{B: [3]float64{0.3306, 4.3356, 0}, C: [3]float64{0.030625, 0.011236, 0}}
+)
+
+// LambdaD is the Fraunhofer d line, used to evaluate an IORModel outside of
+// spectral mode.
+const LambdaD = 587.6
+
+// planck returns the spectral radiance of a blackbody at temperature kelvin
+// and wavelength lambda in nm, up to a constant factor.
+func planck(lambda, kelvin float64) float64 {
+	const c2 = 1.4387769e7 // second radiation constant, nm K
+	l := lambda / 1000     // um, to keep the powers in range
+	return 1 / (l * l * l * l * l * (math.Exp(c2/(lambda*kelvin)) - 1))
+}
+
+// BlackbodyRGB returns the linear RGB color of a blackbody at temperature
+// kelvin, with unit luminance.
+func BlackbodyRGB(kelvin float64) vecmath.Color {
+	var c vecmath.Color
+	for lambda := LambdaMin + 0.5; lambda < LambdaMax; lambda++ {
+		c = c.Add(xyzToRGB(cieXYZ(lambda)).MulS(planck(lambda, kelvin)))
+	}
+	return c.DivS(c.Luminance())
+}
+
+// WhiteBalance returns the per channel gains that render light of a
+// blackbody at temperature kelvin as the color of daylight at 6504 K, the
+// sRGB white point, keeping its luminance.
+func WhiteBalance(kelvin float64) vecmath.Color {
+	return BlackbodyRGB(6504).Div(BlackbodyRGB(kelvin))
+}
@@ -0,0 +1,109 @@
+package spectrum
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func vecAlmostEqual(a, b vecmath.Vec3) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
+
+// integrateFilm averages SpectrumToRGB over the visible range on a 1nm grid,
+// the deterministic equivalent of averaging many sampled wavelengths.
+func integrateFilm(spectrum func(lambda float64) float64) vecmath.Color {
+	var (
+		sum vecmath.Color
+		n   float64
+	)
+	for lambda := LambdaMin + 0.5; lambda < LambdaMax; lambda++ {
+		sum = sum.Add(SpectrumToRGB(spectrum(lambda), lambda))
+		n++
+	}
+	return sum.DivS(n)
+}
+
+func TestSpectrumWhiteIsWhite(t *testing.T) {
+	got := integrateFilm(func(float64) float64 { return 1 })
+	if !vecAlmostEqual(got, vecmath.Color{X: 1, Y: 1, Z: 1}) {
+		t.Fatalf("unit spectrum = %#v, want white", got)
+	}
+}
+
+func TestIntegrateRGBMatchesFilm(t *testing.T) {
+	for name, f := range map[string]func(float64) float64{
+		"flat":  func(float64) float64 { return 0.7 },
+		"ramp":  func(lambda float64) float64 { return (lambda - LambdaMin) / (LambdaMax - LambdaMin) },
+		"comb":  func(lambda float64) float64 { return 0.5 + 0.5*math.Cos(lambda/7) },
+		"brown": func(lambda float64) float64 { return RGBToSpectrum(vecmath.Color{X: 0.4, Y: 0.2, Z: 0.1}, lambda) },
+	} {
+		want := integrateFilm(f)
+		if got := IntegrateRGB(f, int(LambdaMax-LambdaMin)); !vecAlmostEqual(got, want) {
+			t.Fatalf("%s: IntegrateRGB on a 1nm grid = %#v, want %#v", name, got, want)
+		}
+	}
+}
+
+func TestIntegrateRGBCoarse(t *testing.T) {
+	// a few wavelengths suffice for smooth spectra
+	got := IntegrateRGB(func(float64) float64 { return 1 }, 16)
+	if d := got.Sub(vecmath.Color{X: 1, Y: 1, Z: 1}).Abs(); d.X > 0.02 || d.Y > 0.02 || d.Z > 0.02 {
+		t.Fatalf("unit spectrum on 16 wavelengths = %#v, want white", got)
+	}
+}
+
+func TestRGBSpectrumRoundTrip(t *testing.T) {
+	for _, c := range []vecmath.Color{{X: 0.5, Y: 0.5, Z: 0.5}, {X: 0.7, Y: 0.6, Z: 0.5}, {X: 0.4, Y: 0.2, Z: 0.1}, {X: 0.5, Y: 0.7, Z: 1.0}} {
+		got := integrateFilm(func(lambda float64) float64 { return RGBToSpectrum(c, lambda) })
+		if d := got.Sub(c).Abs(); d.X > 1e-3 || d.Y > 1e-3 || d.Z > 1e-3 {
+			t.Fatalf("round trip of %#v = %#v", c, got)
+		}
+	}
+}
+
+func TestSellmeierBK7(t *testing.T) {
+	if n := BK7.IOR(LambdaD); math.Abs(n-1.5168) > 1e-4 {
+		t.Fatalf("BK7 n_d = %v, want 1.5168", n)
+	}
+	if BK7.IOR(450) <= BK7.IOR(650) {
+		t.Fatalf("expected normal dispersion, n(450)=%v n(650)=%v", BK7.IOR(450), BK7.IOR(650))
+	}
+}
+
+func TestCauchy(t *testing.T) {
+	c := Cauchy{A: 1.5, B: 0.004}
+	if n := c.IOR(500); !almostEqual(n, 1.516) {
+		t.Fatalf("Cauchy IOR(500) = %v, want 1.516", n)
+	}
+}
+
+func TestWhiteBalance(t *testing.T) {
+	// warm light is red, cool light blue
+	if c := BlackbodyRGB(3000); c.X <= c.Z {
+		t.Errorf("3000 K = %v, want red over blue", c)
+	}
+	if c := BlackbodyRGB(10000); c.Z <= c.X {
+		t.Errorf("10000 K = %v, want blue over red", c)
+	}
+
+	if g := WhiteBalance(6504); !almostEqual(g.X, 1) || !almostEqual(g.Y, 1) || !almostEqual(g.Z, 1) {
+		t.Errorf("WhiteBalance(6504) = %v, want no change", g)
+	}
+	for _, k := range []float64{2700, 4000, 9000} {
+		var (
+			got  = BlackbodyRGB(k).Mul(WhiteBalance(k))
+			want = BlackbodyRGB(6504)
+		)
+		if !almostEqual(got.Luminance(), 1) || math.Abs(got.X/got.Z-want.X/want.Z) > 1e-6 {
+			t.Errorf("%v K balanced to %v, want the hue of %v", k, got, want)
+		}
+	}
+}
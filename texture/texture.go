@@ -0,0 +1,135 @@
+// Package texture maps surface coordinates to values that vary across an
+// object, such as bump heights and normal maps.
+package texture
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+var (
+	_ Scalar = ScalarFunc(nil)
+	_ Scalar = (*Image)(nil)
+)
+
+// Scalar is a texture of single values, for example heights for a bump map.
+// u and v are the surface coordinates of p, see geometry.HitRecord.
+type Scalar interface {
+	Value(u, v float64, p vecmath.Point3) float64
+}
+
+// ScalarFunc adapts an ordinary function, such as a procedural pattern, to
+// Scalar.
+type ScalarFunc func(u, v float64, p vecmath.Point3) float64
+
+func (f ScalarFunc) Value(u, v float64, p vecmath.Point3) float64 {
+	return f(u, v, p)
+}
+
+// Image is a texture read from an image, with u running left to right and v
+// bottom to top. It repeats outside [0, 1] and is filtered bilinearly.
+// Samples are kept as stored, without gamma decoding, as bump and normal
+// maps hold data rather than colors.
+type Image struct {
+	width, height int
+
+	// row-major from the top, not premultiplied
+	texels []vecmath.Color
+	alpha  []float64
+}
+
+func NewImage(img image.Image) *Image {
+	var (
+		b = img.Bounds()
+		n = b.Dx() * b.Dy()
+		t = &Image{width: b.Dx(), height: b.Dy(), texels: make([]vecmath.Color, 0, n), alpha: make([]float64, 0, n)}
+	)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := nrgba(img, x, y)
+			t.texels = append(t.texels, vecmath.Color{X: float64(c.R), Y: float64(c.G), Z: float64(c.B)}.DivS(0xffff))
+			t.alpha = append(t.alpha, float64(c.A)/0xffff)
+		}
+	}
+	return t
+}
+
+// Color returns the texture's color at (u, v).
+func (t *Image) Color(u, v float64) vecmath.Color {
+	var (
+		k, w = t.bilinear(u, v)
+		c    vecmath.Color
+	)
+	for n := range k {
+		c = c.Add(t.texels[k[n]].MulS(w[n]))
+	}
+	return c
+}
+
+// Alpha returns the texture's opacity at (u, v).
+func (t *Image) Alpha(u, v float64) float64 {
+	var (
+		k, w = t.bilinear(u, v)
+		a    float64
+	)
+	for n := range k {
+		a += t.alpha[k[n]] * w[n]
+	}
+	return a
+}
+
+// AlphaChannel returns the texture's opacity as a Scalar, for example for a
+// material.Cutout.
+func (t *Image) AlphaChannel() Scalar {
+	return ScalarFunc(func(u, v float64, _ vecmath.Point3) float64 {
+		return t.Alpha(u, v)
+	})
+}
+
+// bilinear returns the indices of the four texels around (u, v) and their
+// weights.
+func (t *Image) bilinear(u, v float64) (k [4]int, w [4]float64) {
+	// texel centers sit at half integer coordinates
+	var (
+		x      = u*float64(t.width) - 0.5
+		y      = (1-v)*float64(t.height) - 0.5
+		x0, y0 = math.Floor(x), math.Floor(y)
+		fx, fy = x - x0, y - y0
+		i0, i1 = wrap(int(x0), t.width), wrap(int(x0)+1, t.width)
+		j0, j1 = wrap(int(y0), t.height), wrap(int(y0)+1, t.height)
+	)
+	k = [4]int{j0*t.width + i0, j0*t.width + i1, j1*t.width + i0, j1*t.width + i1}
+	w = [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy}
+	return k, w
+}
+
+// Value returns the mean of the color channels at (u, v), so that a
+// grayscale image can serve as a height map.
+func (t *Image) Value(u, v float64, _ vecmath.Point3) float64 {
+	return t.Color(u, v).Sum() / 3
+}
+
+// nrgba returns the color of img at (x, y) without premultiplied alpha,
+// keeping the color of clear pixels where img stores it, as PNGs do.
+func nrgba(img image.Image, x, y int) color.NRGBA64 {
+	switch m := img.(type) {
+	case *image.NRGBA:
+		c := m.NRGBAAt(x, y)
+		return color.NRGBA64{R: uint16(c.R) * 0x101, G: uint16(c.G) * 0x101, B: uint16(c.B) * 0x101, A: uint16(c.A) * 0x101}
+	case *image.NRGBA64:
+		return m.NRGBA64At(x, y)
+	}
+	return color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+}
+
+// wrap returns i modulo n, in [0, n).
+func wrap(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
@@ -0,0 +1,65 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/mhv2109/RayTracing/vecmath"
+)
+
+func TestImage(t *testing.T) {
+	// black on the left, white on the right
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(1, 0, color.Gray{Y: 255})
+	img.SetGray(1, 1, color.Gray{Y: 255})
+	tex := NewImage(img)
+
+	tests := []struct{ u, v, want float64 }{
+		{0.25, 0.5, 0},  // left texel center
+		{0.75, 0.5, 1},  // right texel center
+		{0.5, 0.5, 0.5}, // between them
+		{1.25, 0.25, 0}, // repeats
+		{0, 0.5, 0.5},   // wraps around the edge
+		{-0.25, 0.9, 1}, // and backwards
+	}
+	for _, tt := range tests {
+		if got := tex.Value(tt.u, tt.v, vecmath.Point3{}); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Value(%v, %v) = %v, want %v", tt.u, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestImageOrientation(t *testing.T) {
+	// v runs bottom to top: the top row is v = 1
+	img := image.NewRGBA(image.Rect(0, 0, 1, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(0, 1, color.RGBA{B: 255, A: 255})
+	tex := NewImage(img)
+	if c := tex.Color(0.5, 0.75); c != (vecmath.Color{X: 1, Y: 0, Z: 0}) {
+		t.Errorf("top = %v, want red", c)
+	}
+	if c := tex.Color(0.5, 0.25); c != (vecmath.Color{X: 0, Y: 0, Z: 1}) {
+		t.Errorf("bottom = %v, want blue", c)
+	}
+}
+
+func TestImageAlpha(t *testing.T) {
+	// opaque on the left, clear on the right
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 0})
+	tex := NewImage(img)
+
+	alpha := tex.AlphaChannel()
+	for _, tt := range []struct{ u, want float64 }{{0.25, 1}, {0.5, 0.5}, {0.75, 0}} {
+		if got := alpha.Value(tt.u, 0.5, vecmath.Point3{}); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("alpha at u = %v: %v, want %v", tt.u, got, tt.want)
+		}
+	}
+	// color is not premultiplied, so clear texels keep theirs
+	if c := tex.Color(0.75, 0.5); c != (vecmath.Color{X: 0, Y: 1, Z: 0}) {
+		t.Errorf("clear texel color = %v, want green", c)
+	}
+}
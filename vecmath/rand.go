@@ -0,0 +1,32 @@
+package vecmath
+
+import (
+	"math/rand/v2"
+)
+
+// Rand is a seeded source of the random numbers a path is sampled with, so
+// that a render can be reproduced, and resumed, from its seed. Methods on a
+// nil *Rand draw from the auto-seeded global source instead.
+type Rand rand.Rand
+
+// NewRand returns a Rand drawing the stream of numbers selected by seed and
+// stream; streams of the same seed are independent.
+func NewRand(seed, stream uint64) *Rand {
+	return (*Rand)(rand.New(rand.NewPCG(seed, stream)))
+}
+
+// Float64 returns a number in [0, 1).
+func (r *Rand) Float64() float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return (*rand.Rand)(r).Float64()
+}
+
+// IntN returns a number in [0, n).
+func (r *Rand) IntN(n int) int {
+	if r == nil {
+		return rand.IntN(n)
+	}
+	return (*rand.Rand)(r).IntN(n)
+}
@@ -0,0 +1,286 @@
+// Package vecmath provides the 3D vectors, colors and matrices used
+// throughout the renderer.
+package vecmath
+
+import (
+	"math"
+)
+
+// TODO: make Point3 and Color distinct types without redeclaring each method
+type Point3 = Vec3
+type Color = Vec3
+
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+func (v Vec3) Abs() Vec3 {
+	return Vec3{math.Abs(v.X), math.Abs(v.Y), math.Abs(v.Z)}
+}
+
+func (v Vec3) Sum() float64 {
+	return v.X + v.Y + v.Z
+}
+
+func (v Vec3) Neg() Vec3 {
+	return Vec3{-v.X, -v.Y, -v.Z}
+}
+
+func (v Vec3) Add(o Vec3) Vec3 {
+	return Vec3{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+func (v Vec3) AddS(t float64) Vec3 {
+	return Vec3{v.X + t, v.Y + t, v.Z + t}
+}
+
+func (v Vec3) Sub(o Vec3) Vec3 {
+	return Vec3{v.X - o.X, v.Y - o.Y, v.Z - o.Z}
+}
+
+func (v Vec3) SubS(t float64) Vec3 {
+	return Vec3{v.X - t, v.Y - t, v.Z - t}
+}
+
+func (v Vec3) MulS(t float64) Vec3 {
+	return Vec3{v.X * t, v.Y * t, v.Z * t}
+}
+
+func (v Vec3) Mul(o Vec3) Vec3 {
+	return Vec3{v.X * o.X, v.Y * o.Y, v.Z * o.Z}
+}
+
+func (v Vec3) DivS(t float64) Vec3 {
+	if t == 0 {
+		panic("Vec3.DivS: division by zero")
+	}
+	return Vec3{v.X / t, v.Y / t, v.Z / t}
+}
+
+func (v Vec3) Div(o Vec3) Vec3 {
+	if o.X == 0 || o.Y == 0 || o.Z == 0 {
+		panic("Vec3.Div: division by zero")
+	}
+	return Vec3{v.X / o.X, v.Y / o.Y, v.Z / o.Z}
+}
+
+func (v Vec3) Len() float64 {
+	return math.Sqrt(v.LenSq())
+}
+
+func (v Vec3) LenSq() float64 {
+	return v.X*v.X + v.Y*v.Y + v.Z*v.Z
+}
+
+func (v Vec3) Sq() Vec3 {
+	return Vec3{v.X * v.X, v.Y * v.Y, v.Z * v.Z}
+}
+
+func (v Vec3) Dot(o Vec3) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+func (v Vec3) Cross(o Vec3) Vec3 {
+	return Vec3{
+		v.Y*o.Z - v.Z*o.Y,
+		v.Z*o.X - v.X*o.Z,
+		v.X*o.Y - v.Y*o.X,
+	}
+}
+
+func (v Vec3) Unit() Vec3 {
+	return v.MulS(1 / v.Len())
+}
+
+func (v Vec3) NearZero() bool {
+	const s = 1e-8
+	v = v.Abs()
+	return v.X < s && v.Y < s && v.Z < s
+}
+
+// Luminance returns the relative luminance of a linear Rec. 709 color.
+func (v Vec3) Luminance() float64 {
+	return 0.2126*v.X + 0.7152*v.Y + 0.0722*v.Z
+}
+
+type RGB struct {
+	R, G, B int
+}
+
+func (v Vec3) RGB(scale float64) RGB {
+	return v.rgb(v.DivS(scale))
+}
+
+func (Vec3) rgb(v Vec3) RGB {
+	return RGB{
+		R: int(255.999 * v.clamp(math.Sqrt(math.Max(0, v.X)), 0.0, 0.999)),
+		G: int(255.999 * v.clamp(math.Sqrt(math.Max(0, v.Y)), 0.0, 0.999)),
+		B: int(255.999 * v.clamp(math.Sqrt(math.Max(0, v.Z)), 0.0, 0.999)),
+	}
+}
+
+func (Vec3) clamp(x, min, max float64) float64 {
+	if x < min {
+		return min
+	} else if x > max {
+		return max
+	}
+	return x
+}
+
+func RandomVec3(rng *Rand, min, max float64) Vec3 {
+	r1 := rng.Float64()
+	r2 := rng.Float64()
+	r3 := rng.Float64()
+	scale := max - min
+	return Vec3{
+		min + r1*scale,
+		min + r2*scale,
+		min + r3*scale,
+	}
+}
+
+func RandomVec3InUnitSphere(rng *Rand) Vec3 {
+	for {
+		// Rejection sampling in cube [-1,1]^3, identical distribution to the
+		// previous implementation but avoids an intermediate Vec3 before the
+		// length-squared check.
+		x := -1 + 2*rng.Float64()
+		y := -1 + 2*rng.Float64()
+		z := -1 + 2*rng.Float64()
+		if x*x+y*y+z*z < 1 {
+			return Vec3{x, y, z}
+		}
+	}
+}
+
+// RandomVec3InUnitDisk returns a uniformly distributed point in the unit
+// disk in the XY plane. It uses the concentric mapping, which keeps
+// stratified samples well spread, rather than rejection.
+func RandomVec3InUnitDisk(rng *Rand) Vec3 {
+	var (
+		a = -1 + 2*rng.Float64()
+		b = -1 + 2*rng.Float64()
+	)
+	if a == 0 && b == 0 {
+		return Vec3{}
+	}
+	var r, theta float64
+	if math.Abs(a) > math.Abs(b) {
+		r, theta = a, math.Pi/4*(b/a)
+	} else {
+		r, theta = b, math.Pi/2-math.Pi/4*(a/b)
+	}
+	return Vec3{r * math.Cos(theta), r * math.Sin(theta), 0}
+}
+
+func RandomUnitVec3(rng *Rand) Vec3 {
+	return RandomVec3InUnitSphere(rng).Unit()
+}
+
+// RandomCosineDirection returns a unit vector in the +Z hemisphere, distributed
+// proportionally to the cosine of its angle with +Z.
+func RandomCosineDirection(rng *Rand) Vec3 {
+	var (
+		r1  = rng.Float64()
+		r2  = rng.Float64()
+		phi = 2 * math.Pi * r1
+		sr2 = math.Sqrt(r2)
+	)
+	return Vec3{math.Cos(phi) * sr2, math.Sin(phi) * sr2, math.Sqrt(1 - r2)}
+}
+
+// ONB is an orthonormal basis built around the W axis.
+type ONB struct {
+	U, V, W Vec3
+}
+
+// NewONB builds an orthonormal basis whose W axis is the unit vector n.
+func NewONB(n Vec3) ONB {
+	w := n.Unit()
+	a := Vec3{1, 0, 0}
+	if math.Abs(w.X) > 0.9 {
+		a = Vec3{0, 1, 0}
+	}
+	v := w.Cross(a).Unit()
+	u := w.Cross(v)
+	return ONB{u, v, w}
+}
+
+// NewTangentONB builds an orthonormal basis whose W axis is the unit vector n
+// and whose U axis is t made perpendicular to n, so that anisotropic shading
+// follows the surface parameterization. It falls back to NewONB when t is
+// zero or parallel to n.
+func NewTangentONB(n, t Vec3) ONB {
+	w := n.Unit()
+	u := t.Sub(w.MulS(w.Dot(t)))
+	if u.NearZero() {
+		return NewONB(n)
+	}
+	u = u.Unit()
+	return ONB{u, w.Cross(u), w}
+}
+
+// ToWorld transforms a vector expressed in the basis into world space.
+func (b ONB) ToWorld(a Vec3) Vec3 {
+	return b.U.MulS(a.X).Add(b.V.MulS(a.Y)).Add(b.W.MulS(a.Z))
+}
+
+// ToLocal transforms a world-space vector into the basis.
+func (b ONB) ToLocal(a Vec3) Vec3 {
+	return Vec3{a.Dot(b.U), a.Dot(b.V), a.Dot(b.W)}
+}
+
+// Mat3 is a row-major 3x3 matrix.
+type Mat3 [3][3]float64
+
+// MulV returns the matrix-vector product m v.
+func (m Mat3) MulV(v Vec3) Vec3 {
+	return Vec3{
+		m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// Mul returns the matrix product m o.
+func (m Mat3) Mul(o Mat3) (p Mat3) {
+	for i := range 3 {
+		for j := range 3 {
+			for k := range 3 {
+				p[i][j] += m[i][k] * o[k][j]
+			}
+		}
+	}
+	return
+}
+
+// Transpose returns the transpose of m.
+func (m Mat3) Transpose() (t Mat3) {
+	for i := range 3 {
+		for j := range 3 {
+			t[i][j] = m[j][i]
+		}
+	}
+	return
+}
+
+// Inverse inverts m by cofactor expansion.
+func (m Mat3) Inverse() (inv Mat3) {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if det == 0 {
+		panic("Inverse: singular matrix")
+	}
+	for i := range 3 {
+		for j := range 3 {
+			var (
+				r0, r1 = (j + 1) % 3, (j + 2) % 3
+				c0, c1 = (i + 1) % 3, (i + 2) % 3
+			)
+			inv[i][j] = (m[r0][c0]*m[r1][c1] - m[r0][c1]*m[r1][c0]) / det
+		}
+	}
+	return
+}
@@ -1,6 +1,19 @@
-package main
+package vecmath
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
+
+const floatEps = 1e-6
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < floatEps
+}
+
+func vecAlmostEqual(a, b Vec3) bool {
+	return almostEqual(a.X, b.X) && almostEqual(a.Y, b.Y) && almostEqual(a.Z, b.Z)
+}
 
 func TestSumPoint(t *testing.T) {
 	p1 := Point3{1, 2, 3}
@@ -201,3 +214,41 @@ func TestLenColor(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestONBRoundTrip(t *testing.T) {
+	for _, n := range []Vec3{{0, 0, 1}, {1, 0, 0}, {1, 2, 3}} {
+		onb := NewONB(n)
+		if !vecAlmostEqual(onb.W, n.Unit()) {
+			t.Fatalf("W = %#v, want %#v", onb.W, n.Unit())
+		}
+		a := Vec3{0.3, -0.2, 0.9}
+		if got := onb.ToLocal(onb.ToWorld(a)); !vecAlmostEqual(got, a) {
+			t.Fatalf("round trip = %#v, want %#v", got, a)
+		}
+	}
+}
+
+func TestTangentONB(t *testing.T) {
+	var (
+		n   = Vec3{0, 0, 2}
+		onb = NewTangentONB(n, Vec3{3, 0, 1})
+	)
+	if !vecAlmostEqual(onb.U, Vec3{1, 0, 0}) || !vecAlmostEqual(onb.V, Vec3{0, 1, 0}) || !vecAlmostEqual(onb.W, Vec3{0, 0, 1}) {
+		t.Fatalf("onb = %#v, want the standard basis", onb)
+	}
+	if got := onb.U.Cross(onb.V); !vecAlmostEqual(got, onb.W) {
+		t.Fatalf("U x V = %#v, want W = %#v", got, onb.W)
+	}
+
+	for _, tangent := range []Vec3{{}, {0, 0, 5}} {
+		if got, want := NewTangentONB(n, tangent), NewONB(n); got != want {
+			t.Fatalf("NewTangentONB(%#v) = %#v, want fallback %#v", tangent, got, want)
+		}
+	}
+}
+
+func TestRGBClampsNegative(t *testing.T) {
+	if got := (Color{-0.1, 0.25, 4}).RGB(1); got != (RGB{0, 127, 255}) {
+		t.Fatalf("RGB = %#v, want {0, 127, 255}", got)
+	}
+}